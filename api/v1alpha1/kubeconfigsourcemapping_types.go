@@ -0,0 +1,104 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OwnershipPolicy describes how a kubeconfig secret must be owned by the
+// control plane object referenced by the cluster.
+// +kubebuilder:validation:Enum=None;Owner;Controller
+type OwnershipPolicy string
+
+const (
+	// OwnershipNone does not check the owner references of the secret.
+	OwnershipNone OwnershipPolicy = "None"
+
+	// OwnershipOwner requires the control plane to be one of the owners of
+	// the secret.
+	OwnershipOwner OwnershipPolicy = "Owner"
+
+	// OwnershipController requires the control plane to be the managing
+	// controller of the secret.
+	OwnershipController OwnershipPolicy = "Controller"
+)
+
+// ControlPlaneSelector selects the control plane references of the clusters
+// handled by a KubeconfigSourceMapping.
+type ControlPlaneSelector struct {
+	// Group is the API group of the control plane, e.g.
+	// "controlplane.cluster.x-k8s.io".
+	// +kubebuilder:validation:MinLength=1
+	Group string `json:"group"`
+
+	// Kind is the kind of the control plane, e.g. "KubeadmControlPlane".
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Versions are the API versions of the control plane handled by the
+	// mapping. All versions are handled when empty.
+	// +optional
+	Versions []string `json:"versions,omitempty"`
+}
+
+// KubeconfigSourceMappingSpec defines where the kubeconfig of the clusters
+// with a given control plane can be found, and how it is validated.
+type KubeconfigSourceMappingSpec struct {
+	// ControlPlane selects the control planes handled by the mapping.
+	ControlPlane ControlPlaneSelector `json:"controlPlane"`
+
+	// SecretNameTemplate is a Go template rendering the name of the
+	// kubeconfig secret, which lives in the namespace of the control plane.
+	// The template is given the ".Cluster" and ".ControlPlane" objects, each
+	// with a ".Name" and ".Namespace", e.g.
+	// "{{ .ControlPlane.Name }}-user-kubeconfig".
+	// +kubebuilder:validation:MinLength=1
+	SecretNameTemplate string `json:"secretNameTemplate"`
+
+	// Key is the key of the kubeconfig within the secret.
+	// +kubebuilder:default=value
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// SecretType is the type the secret must have. Any type is accepted
+	// when empty.
+	// +optional
+	SecretType string `json:"secretType,omitempty"`
+
+	// RequireClusterNameLabel requires the secret to carry the
+	// "cluster.x-k8s.io/cluster-name" label with the name of the cluster.
+	// +optional
+	RequireClusterNameLabel bool `json:"requireClusterNameLabel,omitempty"`
+
+	// Ownership describes how the secret must be owned by the control plane.
+	// +kubebuilder:default=Controller
+	// +optional
+	Ownership OwnershipPolicy `json:"ownership,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Group",type=string,JSONPath=`.spec.controlPlane.group`
+// +kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.controlPlane.kind`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// KubeconfigSourceMapping declares how capargo finds and validates the
+// kubeconfig of clusters whose control plane is not supported natively.
+type KubeconfigSourceMapping struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KubeconfigSourceMappingSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KubeconfigSourceMappingList contains a list of KubeconfigSourceMapping.
+type KubeconfigSourceMappingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubeconfigSourceMapping `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubeconfigSourceMapping{}, &KubeconfigSourceMappingList{})
+}
@@ -0,0 +1,103 @@
+//go:build !ignore_autogenerated
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneSelector) DeepCopyInto(out *ControlPlaneSelector) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneSelector.
+func (in *ControlPlaneSelector) DeepCopy() *ControlPlaneSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeconfigSourceMapping) DeepCopyInto(out *KubeconfigSourceMapping) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeconfigSourceMapping.
+func (in *KubeconfigSourceMapping) DeepCopy() *KubeconfigSourceMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeconfigSourceMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubeconfigSourceMapping) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeconfigSourceMappingList) DeepCopyInto(out *KubeconfigSourceMappingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubeconfigSourceMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeconfigSourceMappingList.
+func (in *KubeconfigSourceMappingList) DeepCopy() *KubeconfigSourceMappingList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeconfigSourceMappingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubeconfigSourceMappingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeconfigSourceMappingSpec) DeepCopyInto(out *KubeconfigSourceMappingSpec) {
+	*out = *in
+	in.ControlPlane.DeepCopyInto(&out.ControlPlane)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeconfigSourceMappingSpec.
+func (in *KubeconfigSourceMappingSpec) DeepCopy() *KubeconfigSourceMappingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeconfigSourceMappingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
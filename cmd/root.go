@@ -4,8 +4,10 @@ import (
 	"context"
 	"flag"
 	"os"
+	"strings"
 	"time"
 
+	capargov1alpha1 "github.com/superorbital/capargo/api/v1alpha1"
 	"github.com/superorbital/capargo/internal/controller"
 	"github.com/superorbital/capargo/pkg/common"
 	"github.com/superorbital/capargo/pkg/providers"
@@ -18,10 +20,15 @@ import (
 	kubeadmv1beta1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	clientconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -52,9 +59,31 @@ var opts = zap.Options{}
 var (
 	clusterID        string
 	clusterNamespace string
+	namespaceLabels  string
+	clusterLabels    string
 	argoNamespace    string
 	workers          int
 	timeout          time.Duration
+	inClusterServer  bool
+	allowGeneric     bool
+	waitForApps      bool
+	adoptionPolicy   string
+	metricsAddr      string
+
+	gcEnabled     bool
+	gcInterval    time.Duration
+	gcGracePeriod time.Duration
+	gcDryRun      bool
+
+	argoClusterNameTemplate string
+	secretNameTemplate      string
+
+	namespaceProjects map[string]string
+
+	propagateLabels             []string
+	propagateLabelPrefixes      []string
+	propagateAnnotations        []string
+	propagateAnnotationPrefixes []string
 )
 
 // Scheme
@@ -72,6 +101,20 @@ var rootCmd = &cobra.Command{
 			ClusterNamespace: clusterNamespace,
 			ArgoNamespace:    argoNamespace,
 			Timeout:          timeout,
+			InClusterServer:  inClusterServer,
+
+			WaitForApplications: waitForApps,
+			AdoptionPolicy:      types.AdoptionPolicy(adoptionPolicy),
+
+			ArgoClusterNameTemplate: argoClusterNameTemplate,
+			SecretNameTemplate:      secretNameTemplate,
+
+			NamespaceProjects: namespaceProjects,
+
+			PropagateLabels:             propagateLabels,
+			PropagateLabelPrefixes:      propagateLabelPrefixes,
+			PropagateAnnotations:        propagateAnnotations,
+			PropagateAnnotationPrefixes: propagateAnnotationPrefixes,
 		}
 		// Logger options
 		logf.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
@@ -89,18 +132,82 @@ var rootCmd = &cobra.Command{
 			"build time", b.BuildTime,
 		)
 
+		// The ID is written as a label value on the ArgoCD secrets
+		if errs := validation.IsValidLabelValue(clusterID); len(errs) > 0 {
+			logger.Error(nil, "invalid --id", "id", clusterID, "errors", errs)
+			os.Exit(1)
+		}
+
+		switch o.AdoptionPolicy {
+		case types.AdoptionPolicyAdopt, types.AdoptionPolicySkip, types.AdoptionPolicyFail:
+		default:
+			logger.Error(nil, "invalid --adoption-policy", "policy", adoptionPolicy)
+			os.Exit(1)
+		}
+
+		// Parse the cluster selector
+		if clusterLabels != "" {
+			selector, err := labels.Parse(clusterLabels)
+			if err != nil {
+				logger.Error(err, "invalid --cluster-selector")
+				os.Exit(1)
+			}
+			o.ClusterSelector = selector
+		}
+
+		// Reject invalid templates before starting the controller
+		if _, err := controller.ParseNameTemplate("argo cluster name template", argoClusterNameTemplate); err != nil {
+			logger.Error(err, "invalid --argo-cluster-name-template")
+			os.Exit(1)
+		}
+		if _, err := controller.ParseNameTemplate("secret name template", secretNameTemplate); err != nil {
+			logger.Error(err, "invalid --secret-name-template")
+			os.Exit(1)
+		}
+
+		// Resolve the namespaces to watch for clusters
+		restConfig := clientconfig.GetConfigOrDie()
+		namespaces := o.WatchNamespaces()
+		if namespaceLabels != "" {
+			selected, err := selectNamespaces(restConfig, namespaceLabels)
+			if err != nil {
+				logger.Error(err, "could not select cluster namespaces")
+				os.Exit(1)
+			}
+			if len(selected) == 0 {
+				logger.Error(nil, "no namespace matches --cluster-namespace-selector", "selector", namespaceLabels)
+				os.Exit(1)
+			}
+			namespaces = append(namespaces, selected...)
+		}
+		o.ClusterNamespace = strings.Join(namespaces, ",")
+		logger.Info("Watching clusters", "namespaces", namespaces)
+
 		// Initialize controller
-		mgr, err := manager.New(clientconfig.GetConfigOrDie(), manager.Options{
+		mgr, err := manager.New(restConfig, manager.Options{
 			Scheme: scheme,
+			Cache:  cacheOptions(o.ArgoNamespace, namespaces),
 			Controller: config.Controller{
 				MaxConcurrentReconciles: workers,
 			},
+			Metrics: metricsserver.Options{
+				BindAddress: metricsAddr,
+			},
 		})
 		if err != nil {
 			logger.Error(err, "could not create manager")
 			os.Exit(1)
 		}
 
+		reconciler := &controller.ClusterKubeconfigReconciler{
+			Client:  mgr.GetClient(),
+			Options: o,
+			ClusterProvider: providers.ClusterProvider{
+				Client:               mgr.GetClient(),
+				AllowGenericProvider: allowGeneric,
+			},
+			Recorder: mgr.GetEventRecorderFor(common.ControllerName),
+		}
 		err = builder.
 			ControllerManagedBy(mgr).
 			For(&capiv1beta1.Cluster{}).
@@ -108,6 +215,10 @@ var rootCmd = &cobra.Command{
 				handler.EnqueueRequestsFromMapFunc(
 					func(ctx context.Context, obj client.Object) []reconcile.Request {
 						s := obj.(*corev1.Secret)
+						// Ignore the secrets of other capargo instances
+						if id, ok := s.Labels[common.ClusterIDLabel]; ok && id != clusterID {
+							return nil
+						}
 						if _, ok := s.Labels[common.ControllerNameLabel]; ok {
 							var name string
 							var namespace string
@@ -128,18 +239,27 @@ var rootCmd = &cobra.Command{
 						}
 						return nil
 					})).
-			Complete(&controller.ClusterKubeconfigReconciler{
-				Client:  mgr.GetClient(),
-				Options: o,
-				ClusterProvider: providers.ClusterProvider{
-					Client: mgr.GetClient(),
-				},
-			})
+			Complete(reconciler)
 		if err != nil {
 			logger.Error(err, "could not create controller")
 			os.Exit(1)
 		}
 
+		// Remove the ArgoCD cluster secrets of the clusters deleted while
+		// capargo was not running
+		if gcEnabled {
+			err = mgr.Add(&controller.GarbageCollector{
+				ClusterKubeconfigReconciler: reconciler,
+				Interval:                    gcInterval,
+				GracePeriod:                 gcGracePeriod,
+				DryRun:                      gcDryRun,
+			})
+			if err != nil {
+				logger.Error(err, "could not add garbage collector")
+				os.Exit(1)
+			}
+		}
+
 		if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
 			logger.Error(err, "could not start manager")
 			os.Exit(1)
@@ -151,16 +271,77 @@ func init() {
 	_ = corev1.AddToScheme(scheme)
 	_ = capiv1beta1.AddToScheme(scheme)
 	_ = kubeadmv1beta1.AddToScheme(scheme)
+	_ = capargov1alpha1.AddToScheme(scheme)
 	opts.BindFlags(flag.CommandLine)
 	rootCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
-	rootCmd.Flags().StringVar(&clusterID, "id", "kind", "The name of the cluster where capargo is located.")
+	rootCmd.Flags().StringVar(&clusterID, "id", "kind", "The name of the cluster where capargo is located. Only the ArgoCD secrets with the same ID are managed.")
 	rootCmd.Flags().IntVar(&workers, "workers", 3, "The number of concurrent workers available to reconcile the state.")
-	rootCmd.Flags().StringVar(&clusterNamespace, "cluster-namespace", "", "The namespace to watch for clusters.")
+	rootCmd.Flags().StringVar(&clusterNamespace, "cluster-namespace", "", "The comma-separated namespaces to watch for clusters. All namespaces are watched by default.")
+	rootCmd.Flags().StringVar(&namespaceLabels, "cluster-namespace-selector", "", "Label selector of additional namespaces to watch for clusters, resolved on startup.")
+	rootCmd.Flags().StringVar(&clusterLabels, "cluster-selector", "", "Label selector of the clusters to register. All clusters are registered by default.")
+	rootCmd.Flags().StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metrics endpoint binds to. Set to 0 to disable it.")
 	rootCmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "The timeout period for any update action.")
 	rootCmd.Flags().StringVar(&argoNamespace, "argo-namespace", "", "The argo namespace in which to place the secrets.")
+	rootCmd.Flags().BoolVar(&inClusterServer, "in-cluster-server", false, "Use the in-cluster Service address of hosted control planes as the ArgoCD server.")
+	rootCmd.Flags().StringVar(&adoptionPolicy, "adoption-policy", string(types.AdoptionPolicyAdopt), "How to handle ArgoCD cluster secrets not created by capargo for the same name or server: adopt, skip or fail.")
+	rootCmd.Flags().BoolVar(&waitForApps, "wait-for-applications", false, "Wait for the ArgoCD applications targeting a deleted cluster to be removed before removing its ArgoCD cluster secret.")
+	rootCmd.Flags().BoolVar(&gcEnabled, "gc", false, "Remove the ArgoCD cluster secrets labelled with --id whose cluster does not exist anymore, on startup and periodically.")
+	rootCmd.Flags().DurationVar(&gcInterval, "gc-interval", 10*time.Minute, "The interval between two garbage collections. When set to 0, only the startup garbage collection is run, until the orphaned secrets it found have passed the grace period.")
+	rootCmd.Flags().DurationVar(&gcGracePeriod, "gc-grace-period", 5*time.Minute, "How long an ArgoCD cluster secret must have been orphaned before the garbage collection removes it.")
+	rootCmd.Flags().BoolVar(&gcDryRun, "gc-dry-run", false, "Only log the ArgoCD cluster secrets that the garbage collection would remove.")
+	rootCmd.Flags().BoolVar(&allowGeneric, "allow-generic-provider", false, "Register clusters with unsupported control planes using the standard Cluster API kubeconfig secret.")
+	rootCmd.Flags().StringVar(&argoClusterNameTemplate, "argo-cluster-name-template", controller.DefaultArgoClusterNameTemplate, "Go template of the cluster name displayed in ArgoCD. Can use .Cluster.Name, .Cluster.Namespace, .Cluster.Labels, .Cluster.Annotations and .ID.")
+	rootCmd.Flags().StringVar(&secretNameTemplate, "secret-name-template", controller.DefaultSecretNameTemplate, "Go template of the ArgoCD cluster secret name. Can use .Cluster.Name, .Cluster.Namespace, .Cluster.Labels, .Cluster.Annotations and .ID.")
+	rootCmd.Flags().StringToStringVar(&namespaceProjects, "namespace-project", nil, "ArgoCD project of the clusters in a namespace, as namespace=project. Can be repeated.")
+	rootCmd.Flags().StringSliceVar(&propagateLabels, "propagate-label", nil, "Cluster label to copy onto the ArgoCD cluster secret. Can be repeated.")
+	rootCmd.Flags().StringSliceVar(&propagateLabelPrefixes, "propagate-label-prefix", nil, "Prefix of the Cluster labels to copy onto the ArgoCD cluster secret. Can be repeated.")
+	rootCmd.Flags().StringSliceVar(&propagateAnnotations, "propagate-annotation", nil, "Cluster annotation to copy onto the ArgoCD cluster secret. Can be repeated.")
+	rootCmd.Flags().StringSliceVar(&propagateAnnotationPrefixes, "propagate-annotation-prefix", nil, "Prefix of the Cluster annotations to copy onto the ArgoCD cluster secret. Can be repeated.")
 	rootCmd.MarkFlagRequired("argo-namespace")
 }
 
+// cacheOptions restricts the manager cache to the cluster namespaces and to
+// the ArgoCD namespace, unless all namespaces are watched.
+func cacheOptions(argoNamespace string, namespaces []string) cache.Options {
+	if len(namespaces) == 0 {
+		return cache.Options{}
+	}
+	defaultNamespaces := map[string]cache.Config{argoNamespace: {}}
+	clusterNamespaces := map[string]cache.Config{}
+	for _, ns := range namespaces {
+		defaultNamespaces[ns] = cache.Config{}
+		clusterNamespaces[ns] = cache.Config{}
+	}
+	return cache.Options{
+		DefaultNamespaces: defaultNamespaces,
+		ByObject: map[client.Object]cache.ByObject{
+			&capiv1beta1.Cluster{}: {Namespaces: clusterNamespaces},
+		},
+	}
+}
+
+// selectNamespaces returns the names of the namespaces matching the label
+// selector. They are only resolved on startup.
+func selectNamespaces(restConfig *rest.Config, selector string) ([]string, error) {
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	list := corev1.NamespaceList{}
+	if err := c.List(context.Background(), &list, client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		return nil, err
+	}
+	namespaces := []string{}
+	for _, ns := range list.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+	return namespaces, nil
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
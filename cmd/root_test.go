@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("Cache options", func() {
+	It("should watch every namespace by default", func() {
+		Expect(cacheOptions("argocd", nil)).To(Equal(cache.Options{}))
+	})
+
+	It("should restrict the cache to the argo and cluster namespaces", func() {
+		o := cacheOptions("argocd", []string{"team-a", "team-b"})
+		Expect(o.DefaultNamespaces).To(Equal(map[string]cache.Config{
+			"argocd": {},
+			"team-a": {},
+			"team-b": {},
+		}))
+
+		By("only watching clusters in the cluster namespaces")
+		Expect(o.ByObject).To(HaveLen(1))
+		for object, byObject := range o.ByObject {
+			Expect(object).To(BeAssignableToTypeOf(&capiv1beta1.Cluster{}))
+			Expect(byObject.Namespaces).To(Equal(map[string]cache.Config{
+				"team-a": {},
+				"team-b": {},
+			}))
+		}
+	})
+})
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// applicationListAPIVersion and applicationListKind identify the ArgoCD
+// Applications, which are listed as unstructured objects so that they are
+// neither cached nor require the ArgoCD CRDs to be installed.
+const (
+	applicationListAPIVersion = "argoproj.io/v1alpha1"
+	applicationListKind       = "ApplicationList"
+)
+
+// listApplications returns the names of the ArgoCD Applications in the
+// ArgoCD namespace whose destination is one of the ArgoCD cluster secrets,
+// by server address or by name.
+func (c *ClusterKubeconfigReconciler) listApplications(ctx context.Context, secrets []corev1.Secret) ([]string, error) {
+	if len(secrets) == 0 {
+		return nil, nil
+	}
+	list := unstructured.UnstructuredList{}
+	list.SetAPIVersion(applicationListAPIVersion)
+	list.SetKind(applicationListKind)
+	if err := c.List(ctx, &list, client.InNamespace(c.ArgoNamespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	applications := []string{}
+	for _, app := range list.Items {
+		server, _, _ := unstructured.NestedString(app.Object, "spec", "destination", "server")
+		name, _, _ := unstructured.NestedString(app.Object, "spec", "destination", "name")
+		for _, s := range secrets {
+			if (server != "" && server == string(s.Data["server"])) ||
+				(name != "" && name == string(s.Data["name"])) {
+				applications = append(applications, app.GetName())
+				break
+			}
+		}
+	}
+	return applications, nil
+}
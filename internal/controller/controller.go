@@ -4,22 +4,37 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"reflect"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/superorbital/capargo/pkg/common"
 	"github.com/superorbital/capargo/pkg/providers"
 	"github.com/superorbital/capargo/pkg/types"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	argocdcommon "github.com/argoproj/argo-cd/v2/common"
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachinerytypes "k8s.io/apimachinery/pkg/types"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -31,12 +46,28 @@ type ClusterKubeconfigReconciler struct {
 	client.Client
 	types.Options
 	providers.ClusterProvider
+
+	// Recorder emits Events on the clusters. No Event is emitted when it is
+	// nil.
+	Recorder record.EventRecorder
+
+	// pausedClusters holds the clusters seen paused, so that their pause is
+	// only logged once.
+	pausedClusters sync.Map
 }
 
 // Reconcile performs the main logic to create ArgoCD cluster secrets for
 // every managed cluster and its kubeconfig.
 func (c *ClusterKubeconfigReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	logger = logf.FromContext(ctx)
+
+	// ArgoCD cluster secrets may point at clusters outside of the watched
+	// namespaces, which are not in the cache.
+	if !c.isWatchedNamespace(req.Namespace) {
+		logger.V(4).Info("Cluster is not in a watched namespace")
+		return reconcile.Result{}, nil
+	}
+
 	cluster := &capiv1beta1.Cluster{}
 	err := c.Get(ctx, req.NamespacedName, cluster)
 	if err != nil && !errors.IsNotFound(err) {
@@ -44,31 +75,243 @@ func (c *ClusterKubeconfigReconciler) Reconcile(ctx context.Context, req reconci
 	}
 	logger.V(4).Info("Cluster received")
 
-	// Remove the ArgoCD cluster secret if the cluster was deleted.
+	// Remove the ArgoCD cluster secret if the cluster was deleted without
+	// going through the finalizer, unless it was paused, e.g. when it is
+	// moved to another management cluster by clusterctl move.
 	if errors.IsNotFound(err) {
+		forgetCluster(req.Namespace, req.Name)
 		return reconcile.Result{}, c.deleteArgoCluster(ctx, req)
 	}
 
+	// Leave the ArgoCD cluster secret as is while the cluster is paused. The
+	// pause is recorded on the secret, so that it outlives the cluster and
+	// restarts of capargo.
+	paused := annotations.IsPaused(cluster, cluster)
+	changed, err := c.markPaused(ctx, cluster, paused)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if paused {
+		if _, seen := c.pausedClusters.LoadOrStore(req.NamespacedName, struct{}{}); !seen {
+			logger.Info("Cluster is paused, skipping")
+		}
+		return reconcile.Result{}, nil
+	}
+	if _, seen := c.pausedClusters.LoadAndDelete(req.NamespacedName); seen || changed {
+		logger.Info("Cluster is not paused anymore")
+	}
+
+	// Remove the ArgoCD cluster secret while the cluster is being deleted.
+	if !cluster.DeletionTimestamp.IsZero() {
+		return c.finalizeArgoCluster(ctx, cluster)
+	}
+
+	// Remove the ArgoCD cluster secret if the cluster is not selected anymore.
+	if !c.isSelected(cluster) {
+		logger.V(4).Info("Cluster is not selected")
+		forgetCluster(cluster.Namespace, cluster.Name)
+		if err := c.removeArgoCluster(ctx, cluster); err != nil {
+			return reconcile.Result{}, err
+		}
+		if err := c.removeFinalizer(ctx, cluster); err != nil {
+			return reconcile.Result{}, err
+		}
+		if conditions.Get(cluster, ArgoCDRegisteredCondition) == nil {
+			return reconcile.Result{}, nil
+		}
+		patchHelper, err := patch.NewHelper(cluster, c.Client)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		conditions.MarkFalse(cluster, ArgoCDRegisteredCondition, NotSelectedReason,
+			capiv1beta1.ConditionSeverityInfo, "Cluster is not selected",
+		)
+		return reconcile.Result{}, c.patchConditions(ctx, patchHelper, cluster)
+	}
+
 	// Wait until control plane is ready and our kubeconfig has been generated
 	// to create or update the ArgoCD secret.
 	if !cluster.Status.ControlPlaneReady {
+		setRegistered(cluster, false, WaitingForControlPlaneReason)
+		patchHelper, err := patch.NewHelper(cluster, c.Client)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		conditions.MarkFalse(cluster, ArgoCDRegisteredCondition, WaitingForControlPlaneReason,
+			capiv1beta1.ConditionSeverityInfo, "Waiting for the control plane to be ready",
+		)
 		return reconcile.Result{
 			RequeueAfter: 10 * time.Second,
-		}, nil
+		}, c.patchConditions(ctx, patchHelper, cluster)
+	}
+
+	// Make sure that the ArgoCD secret is removed before the cluster is.
+	if err := c.addFinalizer(ctx, cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Report the outcome of the registration in the ArgoCDRegistered
+	// condition.
+	patchHelper, err := patch.NewHelper(cluster, c.Client)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	err = c.createOrUpdateArgoCluster(ctx, cluster)
+	if err != nil {
+		c.markRegistrationFailed(cluster, err)
+	}
+	return reconcile.Result{}, kerrors.NewAggregate([]error{err, c.patchConditions(ctx, patchHelper, cluster)})
+}
+
+// finalizeArgoCluster removes the ArgoCD cluster secrets of a cluster being
+// deleted, optionally once no ArgoCD Application targets it anymore, and then
+// lets the deletion of the cluster proceed.
+func (c *ClusterKubeconfigReconciler) finalizeArgoCluster(ctx context.Context, cluster *capiv1beta1.Cluster) (reconcile.Result, error) {
+	secrets, err := c.listArgoClusterSecrets(ctx, cluster.Namespace, cluster.Name)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if c.WaitForApplications {
+		applications, err := c.listApplications(ctx, secrets)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if len(applications) > 0 {
+			logger.Info("Waiting for ArgoCD applications targeting the cluster to be removed",
+				"applications", applications,
+			)
+			return reconcile.Result{
+				RequeueAfter: 10 * time.Second,
+			}, nil
+		}
+	}
+	forgetCluster(cluster.Namespace, cluster.Name)
+	if err := c.removeArgoCluster(ctx, cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, c.removeFinalizer(ctx, cluster)
+}
+
+// removeArgoCluster removes the ArgoCD cluster secrets of an existing
+// cluster, and reports it in an Event.
+func (c *ClusterKubeconfigReconciler) removeArgoCluster(ctx context.Context, cluster *capiv1beta1.Cluster) error {
+	secrets, err := c.listArgoClusterSecrets(ctx, cluster.Namespace, cluster.Name)
+	if err != nil {
+		return err
+	}
+	for i := range secrets {
+		if err := c.deleteArgoClusterSecret(ctx, &secrets[i]); err != nil {
+			return err
+		}
+		c.event(cluster, corev1.EventTypeNormal, ArgoCDClusterDeletedReason,
+			"Deleted ArgoCD cluster secret %s/%s", secrets[i].Namespace, secrets[i].Name,
+		)
+	}
+	return nil
+}
+
+// markPaused records on the ArgoCD cluster secrets of the cluster whether it
+// is paused, and reports whether any secret changed.
+func (c *ClusterKubeconfigReconciler) markPaused(ctx context.Context, cluster *capiv1beta1.Cluster, paused bool) (bool, error) {
+	secrets, err := c.listArgoClusterSecrets(ctx, cluster.Namespace, cluster.Name)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	for i := range secrets {
+		if isPausedSecret(&secrets[i]) == paused {
+			continue
+		}
+		if err := c.setPaused(ctx, &secrets[i], paused); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// setPaused adds or removes the paused annotation of the ArgoCD cluster
+// secret.
+func (c *ClusterKubeconfigReconciler) setPaused(ctx context.Context, secret *corev1.Secret, paused bool) error {
+	patch := client.MergeFrom(secret.DeepCopy())
+	if paused {
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[common.PausedAnnotation] = "true"
+	} else {
+		delete(secret.Annotations, common.PausedAnnotation)
+	}
+	return c.Patch(ctx, secret, patch)
+}
+
+// isPausedSecret determines whether the ArgoCD cluster secret belongs to a
+// cluster last seen paused.
+func isPausedSecret(secret *corev1.Secret) bool {
+	_, ok := secret.Annotations[common.PausedAnnotation]
+	return ok
+}
+
+// addFinalizer adds the capargo finalizer to the cluster.
+func (c *ClusterKubeconfigReconciler) addFinalizer(ctx context.Context, cluster *capiv1beta1.Cluster) error {
+	if controllerutil.ContainsFinalizer(cluster, common.ClusterFinalizer) {
+		return nil
+	}
+	patch := client.MergeFromWithOptions(cluster.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.AddFinalizer(cluster, common.ClusterFinalizer)
+	return c.Patch(ctx, cluster, patch)
+}
+
+// removeFinalizer removes the capargo finalizer from the cluster.
+func (c *ClusterKubeconfigReconciler) removeFinalizer(ctx context.Context, cluster *capiv1beta1.Cluster) error {
+	if !controllerutil.ContainsFinalizer(cluster, common.ClusterFinalizer) {
+		return nil
 	}
+	patch := client.MergeFromWithOptions(cluster.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.RemoveFinalizer(cluster, common.ClusterFinalizer)
+	return c.Patch(ctx, cluster, patch)
+}
+
+// isWatchedNamespace determines whether the clusters of the namespace are
+// watched by capargo.
+func (c *ClusterKubeconfigReconciler) isWatchedNamespace(namespace string) bool {
+	namespaces := c.WatchNamespaces()
+	return len(namespaces) == 0 || slices.Contains(namespaces, namespace)
+}
 
-	return reconcile.Result{}, c.createOrUpdateArgoCluster(ctx, cluster)
+// isSelected determines whether the cluster should be registered in ArgoCD,
+// i.e. whether it matches the cluster selector and is not skipped.
+func (c *ClusterKubeconfigReconciler) isSelected(cluster *capiv1beta1.Cluster) bool {
+	if skip, err := strconv.ParseBool(cluster.Annotations[common.SkipAnnotation]); err == nil && skip {
+		return false
+	}
+	return c.ClusterSelector == nil || c.ClusterSelector.Matches(labels.Set(cluster.Labels))
 }
 
-// deleteArgoCluster removes the ArgoCD cluster secret from the cluster.
+// deleteArgoCluster removes the ArgoCD cluster secrets of a deleted cluster,
+// except the ones of a cluster deleted while paused.
 func (c *ClusterKubeconfigReconciler) deleteArgoCluster(ctx context.Context, req reconcile.Request) error {
-	secret := corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      req.Namespace + "-" + req.Name,
-			Namespace: c.ArgoNamespace,
-		},
+	secrets, err := c.listArgoClusterSecrets(ctx, req.Namespace, req.Name)
+	if err != nil {
+		return err
+	}
+	for i := range secrets {
+		if isPausedSecret(&secrets[i]) {
+			logger.Info("Paused cluster was deleted, keeping its ArgoCD cluster secret",
+				"secret namespace", secrets[i].GetNamespace(), "secret name", secrets[i].GetName(),
+			)
+			continue
+		}
+		if err := c.deleteArgoClusterSecret(ctx, &secrets[i]); err != nil {
+			return err
+		}
 	}
-	err := c.Delete(ctx, &secret, &client.DeleteOptions{})
+	return nil
+}
+
+// deleteArgoClusterSecret removes an ArgoCD cluster secret from the cluster.
+func (c *ClusterKubeconfigReconciler) deleteArgoClusterSecret(ctx context.Context, secret *corev1.Secret) error {
+	err := c.Delete(ctx, secret, &client.DeleteOptions{})
 	if err != nil && !errors.IsNotFound(err) {
 		return err
 	}
@@ -76,57 +319,208 @@ func (c *ClusterKubeconfigReconciler) deleteArgoCluster(ctx context.Context, req
 	logger.Info("Deleted ArgoCD cluster secret",
 		"secret namespace", secret.GetNamespace(), "secret name", secret.GetName(),
 	)
+	registrations.WithLabelValues(deletedOperation).Inc()
 
 	return nil
 }
 
+// listArgoClusterSecrets returns the ArgoCD cluster secrets created by
+// capargo for the cluster with the given namespace and name. Secret names
+// are templated, so the secrets are matched by their annotations instead.
+func (c *ClusterKubeconfigReconciler) listArgoClusterSecrets(ctx context.Context, namespace, name string) ([]corev1.Secret, error) {
+	list := corev1.SecretList{}
+	if err := c.List(ctx, &list,
+		client.InNamespace(c.ArgoNamespace),
+		client.HasLabels{common.ControllerNameLabel},
+	); err != nil {
+		return nil, err
+	}
+	secrets := []corev1.Secret{}
+	for _, s := range list.Items {
+		if s.Annotations[common.ClusterNameAnnotation] != name ||
+			s.Annotations[common.ClusterNamespaceAnnotation] != namespace {
+			continue
+		}
+		owned, err := c.ownsSecret(ctx, &s)
+		if err != nil {
+			return nil, err
+		}
+		if owned {
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets, nil
+}
+
+// isManagedSecret determines whether the ArgoCD cluster secret was created by
+// capargo.
+func isManagedSecret(secret *corev1.Secret) bool {
+	_, ok := secret.Labels[common.ControllerNameLabel]
+	return ok
+}
+
+// registersCluster determines whether the ArgoCD cluster secret created by
+// capargo registers the cluster. Secrets without the cluster annotations
+// cannot tell, and are assumed to.
+func registersCluster(secret *corev1.Secret, cluster *capiv1beta1.Cluster) bool {
+	name, ok := secret.Annotations[common.ClusterNameAnnotation]
+	if !ok {
+		return true
+	}
+	return name == cluster.Name && secret.Annotations[common.ClusterNamespaceAnnotation] == cluster.Namespace
+}
+
+// sameServer determines whether both ArgoCD cluster secrets register the same
+// API server.
+func sameServer(secret, other *corev1.Secret) bool {
+	return strings.TrimSuffix(string(secret.Data["server"]), "/") ==
+		strings.TrimSuffix(string(other.Data["server"]), "/")
+}
+
+// listUnmanagedSecrets returns the ArgoCD cluster secrets not created by
+// capargo which register the same API server as the secret, under another
+// name.
+func (c *ClusterKubeconfigReconciler) listUnmanagedSecrets(ctx context.Context, secret *corev1.Secret) ([]corev1.Secret, error) {
+	list := corev1.SecretList{}
+	if err := c.List(ctx, &list,
+		client.InNamespace(c.ArgoNamespace),
+		client.MatchingLabels{argocdcommon.LabelKeySecretType: argocdcommon.LabelValueSecretTypeCluster},
+	); err != nil {
+		return nil, err
+	}
+	secrets := []corev1.Secret{}
+	for _, s := range list.Items {
+		if isManagedSecret(&s) || s.Name == secret.Name {
+			continue
+		}
+		if sameServer(&s, secret) {
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets, nil
+}
+
+// ownsSecret determines whether the ArgoCD cluster secret was registered by
+// this instance of capargo, i.e. the one with the same ID. Secrets without an
+// ID were registered by older versions of capargo, possibly from another
+// management cluster sharing the same ArgoCD, and are only claimed when their
+// cluster exists in this one.
+func (c *ClusterKubeconfigReconciler) ownsSecret(ctx context.Context, secret *corev1.Secret) (bool, error) {
+	if id, ok := secret.Labels[common.ClusterIDLabel]; ok {
+		return id == c.ClusterID, nil
+	}
+	name, ok := secret.Annotations[common.ClusterNameAnnotation]
+	if !ok {
+		return false, nil
+	}
+	namespace := secret.Annotations[common.ClusterNamespaceAnnotation]
+	if !c.isWatchedNamespace(namespace) {
+		return false, nil
+	}
+	key := apimachinerytypes.NamespacedName{Name: name, Namespace: namespace}
+	err := c.Get(ctx, key, &capiv1beta1.Cluster{}, &client.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ownershipError reports that the ArgoCD cluster secret belongs to another
+// instance of capargo.
+func (c *ClusterKubeconfigReconciler) ownershipError(secret *corev1.Secret) error {
+	if id, ok := secret.Labels[common.ClusterIDLabel]; ok {
+		return fmt.Errorf("secret %s/%s is managed by the capargo instance with ID %q",
+			secret.Namespace, secret.Name, id,
+		)
+	}
+	return fmt.Errorf("secret %s/%s was registered by an older capargo instance for cluster %s/%s, which does not exist in this management cluster",
+		secret.Namespace, secret.Name,
+		secret.Annotations[common.ClusterNamespaceAnnotation], secret.Annotations[common.ClusterNameAnnotation],
+	)
+}
+
 // createOrUpdateArgoCluster uploads the latest version of the cluster
 // kubeconfig as an ArgoCD cluster secret to the cluster.
 func (c *ClusterKubeconfigReconciler) createOrUpdateArgoCluster(ctx context.Context, cluster *capiv1beta1.Cluster) error {
 	capiSecret := &corev1.Secret{}
-	namespacedName, err := c.GetCapiKubeconfigNamespacedName(cluster)
+	namespacedName, err := c.GetCapiKubeconfigNamespacedName(ctx, cluster)
 	if err != nil {
-		return err
+		return providerError(err)
 	}
 	if err := c.Get(ctx, namespacedName, capiSecret, &client.GetOptions{}); err != nil {
+		if errors.IsNotFound(err) {
+			return &registrationError{reason: KubeconfigNotFoundReason, err: err}
+		}
 		return err
 	}
 	valid, err := c.IsCapiKubeconfig(ctx, capiSecret, cluster)
 	if err != nil {
-		return err
+		return providerError(err)
 	}
 
 	// Ensure that the secret will contain a kubeconfig, and retrieve it.
 	if !valid {
-		return fmt.Errorf("secret %s does not contain kubeconfig",
-			capiSecret.Name,
-		)
+		return &registrationError{
+			reason: InvalidKubeconfigReason,
+			err: fmt.Errorf("secret %s/%s is not a kubeconfig for %s %s",
+				capiSecret.Namespace, capiSecret.Name,
+				cluster.Spec.ControlPlaneRef.Kind, cluster.Spec.ControlPlaneRef.APIVersion,
+			),
+		}
+	}
+	key, err := c.GetCapiKubeconfigKey(ctx, cluster)
+	if err != nil {
+		return providerError(err)
 	}
-	configBytes, ok := capiSecret.Data["value"]
+	configBytes, ok := capiSecret.Data[key]
 	if !ok {
-		return fmt.Errorf("secret %s/%s does not contain key \"value\"",
-			capiSecret.Namespace, capiSecret.Name,
-		)
+		return &registrationError{
+			reason: MissingKubeconfigKeyReason,
+			err: fmt.Errorf("secret %s/%s does not contain key %q",
+				capiSecret.Namespace, capiSecret.Name, key,
+			),
+		}
 	}
 
 	// Create kubeconfig credentials from cluster secret
 	config, err := clientcmd.RESTConfigFromKubeConfig(configBytes)
 	if err != nil {
-		return fmt.Errorf("failed to build restconfig from the secret %s/%s: %v",
-			capiSecret.Namespace, capiSecret.Name, err,
-		)
+		return &registrationError{
+			reason: InvalidKubeconfigReason,
+			err: fmt.Errorf("failed to build restconfig from the secret %s/%s: %v",
+				capiSecret.Namespace, capiSecret.Name, err,
+			),
+		}
+	}
+
+	if err := c.setServer(ctx, cluster, config); err != nil {
+		return err
 	}
 
 	// Build the ArgoCD secret
 	clusterConfig := buildClusterConfigFromRestConfig(config)
+	if clusterConfig.AWSAuthConfig != nil {
+		if err := c.setAWSAuthConfig(ctx, cluster, clusterConfig.AWSAuthConfig); err != nil {
+			return err
+		}
+	}
 	ccJson, err := json.Marshal(clusterConfig)
 	if err != nil {
 		return fmt.Errorf("could not marshal cluster config: %v", err)
 	}
 
+	argoClusterName, err := c.argoClusterName(cluster)
+	if err != nil {
+		return err
+	}
+	secretName, err := c.argoSecretName(cluster)
+	if err != nil {
+		return err
+	}
+
 	newArgoClusterSecret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      cluster.Namespace + "-" + cluster.Name,
+			Name:      secretName,
 			Namespace: c.ArgoNamespace,
 			Annotations: map[string]string{
 				common.ClusterNameAnnotation:      cluster.Name,
@@ -135,36 +529,309 @@ func (c *ClusterKubeconfigReconciler) createOrUpdateArgoCluster(ctx context.Cont
 			Labels: map[string]string{
 				argocdcommon.LabelKeySecretType: argocdcommon.LabelValueSecretTypeCluster,
 				common.ControllerNameLabel:      common.ControllerName,
+				common.ClusterIDLabel:           c.ClusterID,
 			},
 		},
 		Data: map[string][]byte{
-			"name":   []byte(cluster.Name),
+			"name":   []byte(argoClusterName),
 			"server": []byte(config.Host),
 			"config": ccJson,
 		},
 	}
 
+	// Copy the selected Cluster labels and annotations, which cannot
+	// override the ones set by capargo.
+	mergeInto(newArgoClusterSecret.Labels,
+		propagate(cluster.Labels, c.PropagateLabels, c.PropagateLabelPrefixes),
+	)
+	mergeInto(newArgoClusterSecret.Annotations,
+		propagate(cluster.Annotations, c.PropagateAnnotations, c.PropagateAnnotationPrefixes),
+	)
+
+	if project := c.argoProject(cluster); project != "" {
+		newArgoClusterSecret.Data["project"] = []byte(project)
+	}
+
+	if err := setArgoScope(cluster, newArgoClusterSecret.Data); err != nil {
+		return err
+	}
+
 	currentArgoClusterSecret := corev1.Secret{}
 	err = c.Get(ctx, client.ObjectKeyFromObject(&newArgoClusterSecret), &currentArgoClusterSecret, &client.GetOptions{})
 	if err != nil && !errors.IsNotFound(err) {
 		return err
 	}
 
-	if errors.IsNotFound(err) {
-		if err := c.Create(ctx, &newArgoClusterSecret, &client.CreateOptions{}); err != nil {
+	exists := err == nil
+
+	// The secret of a cluster moved from another management cluster, e.g. by
+	// clusterctl move, was left paused by the capargo instance of that
+	// management cluster. It is taken over as long as it registers the same
+	// cluster and API server.
+	movedIn := false
+	if exists && isManagedSecret(&currentArgoClusterSecret) {
+		owned, err := c.ownsSecret(ctx, &currentArgoClusterSecret)
+		if err != nil {
 			return err
 		}
-		logger.Info("Created ArgoCD cluster", "secret", newArgoClusterSecret.GetName())
-	} else {
-		if !reflect.DeepEqual(currentArgoClusterSecret.Data, newArgoClusterSecret.Data) ||
-			currentArgoClusterSecret.Labels[argocdcommon.LabelValueSecretTypeCluster] != newArgoClusterSecret.Labels[argocdcommon.LabelValueSecretTypeCluster] {
-			if err := c.Update(ctx, &newArgoClusterSecret, &client.UpdateOptions{}); err != nil {
+		if !owned {
+			if !isPausedSecret(&currentArgoClusterSecret) ||
+				!registersCluster(&currentArgoClusterSecret, cluster) ||
+				!sameServer(&currentArgoClusterSecret, &newArgoClusterSecret) {
+				return c.ownershipError(&currentArgoClusterSecret)
+			}
+			movedIn = true
+		}
+	}
+
+	// Two clusters may render the same secret name, and would otherwise
+	// overwrite each other's registration.
+	if exists && isManagedSecret(&currentArgoClusterSecret) && !registersCluster(&currentArgoClusterSecret, cluster) {
+		return &registrationError{
+			reason: SecretNameConflictReason,
+			err: fmt.Errorf("secret %s/%s already registers cluster %s/%s",
+				currentArgoClusterSecret.Namespace, currentArgoClusterSecret.Name,
+				currentArgoClusterSecret.Annotations[common.ClusterNamespaceAnnotation],
+				currentArgoClusterSecret.Annotations[common.ClusterNameAnnotation],
+			),
+		}
+	}
+
+	// Apply the adoption policy to the ArgoCD cluster secrets created by
+	// other means for the same name or API server.
+	unmanaged, err := c.listUnmanagedSecrets(ctx, &newArgoClusterSecret)
+	if err != nil {
+		return err
+	}
+	if exists && !isManagedSecret(&currentArgoClusterSecret) {
+		unmanaged = append(unmanaged, currentArgoClusterSecret)
+	}
+	if len(unmanaged) > 0 {
+		names := []string{}
+		for _, s := range unmanaged {
+			names = append(names, s.Name)
+		}
+		switch c.AdoptionPolicy {
+		case types.AdoptionPolicySkip:
+			// Leave the cluster to the secrets created by hand, including
+			// when they appeared after capargo registered it, so that it is
+			// not registered twice.
+			logger.Info("ArgoCD cluster is already registered, skipping", "secrets", names)
+			conditions.MarkFalse(cluster, ArgoCDRegisteredCondition, AlreadyRegisteredReason,
+				capiv1beta1.ConditionSeverityInfo, "ArgoCD cluster is already registered by secrets %v", names,
+			)
+			forgetCluster(cluster.Namespace, cluster.Name)
+			return c.removeArgoCluster(ctx, cluster)
+		case types.AdoptionPolicyFail:
+			return &registrationError{
+				reason: AlreadyRegisteredReason,
+				err: fmt.Errorf("ArgoCD cluster %s is already registered by secrets %v in namespace %s",
+					config.Host, names, c.ArgoNamespace,
+				),
+			}
+		default:
+			logger.Info("Adopting ArgoCD cluster", "secrets", names)
+		}
+	}
+
+	// Secrets written by older versions of capargo with Create and Update
+	// hold their fields under an Update operation, which apply never
+	// prunes. Hand them over to apply first.
+	if exists && isManagedSecret(&currentArgoClusterSecret) {
+		if err := c.upgradeManagedFields(ctx, &currentArgoClusterSecret); err != nil {
+			return err
+		}
+	}
+
+	// Apply the secret, so that the labels, annotations and data set by
+	// other controllers or by hand are left untouched.
+	newArgoClusterSecret.TypeMeta = metav1.TypeMeta{
+		APIVersion: corev1.SchemeGroupVersion.String(),
+		Kind:       "Secret",
+	}
+	if err := c.Patch(ctx, &newArgoClusterSecret, client.Apply,
+		client.FieldOwner(common.ControllerName), client.ForceOwnership,
+	); err != nil {
+		return err
+	}
+	if movedIn {
+		logger.Info("Took over the ArgoCD cluster of a cluster moved from another management cluster",
+			"secret", newArgoClusterSecret.GetName(),
+			"previous ID", currentArgoClusterSecret.Labels[common.ClusterIDLabel],
+		)
+		c.event(cluster, corev1.EventTypeNormal, ArgoCDClusterMovedReason,
+			"Took over ArgoCD cluster secret %s/%s from the capargo instance with ID %q",
+			newArgoClusterSecret.Namespace, newArgoClusterSecret.Name, currentArgoClusterSecret.Labels[common.ClusterIDLabel],
+		)
+		if isPausedSecret(&newArgoClusterSecret) {
+			if err := c.setPaused(ctx, &newArgoClusterSecret, false); err != nil {
 				return err
 			}
-			logger.Info("Updated ArgoCD cluster", "secret", newArgoClusterSecret.GetName())
 		}
 	}
+	if !exists {
+		logger.Info("Created ArgoCD cluster", "secret", newArgoClusterSecret.GetName())
+		c.event(cluster, corev1.EventTypeNormal, ArgoCDClusterCreatedReason,
+			"Created ArgoCD cluster secret %s/%s", newArgoClusterSecret.Namespace, newArgoClusterSecret.Name,
+		)
+		registrations.WithLabelValues(createdOperation).Inc()
+		observeRegistration(cluster)
+	} else if newArgoClusterSecret.ResourceVersion != currentArgoClusterSecret.ResourceVersion {
+		logger.Info("Updated ArgoCD cluster", "secret", newArgoClusterSecret.GetName())
+		c.event(cluster, corev1.EventTypeNormal, ArgoCDClusterUpdatedReason,
+			"Updated ArgoCD cluster secret %s/%s", newArgoClusterSecret.Namespace, newArgoClusterSecret.Name,
+		)
+		registrations.WithLabelValues(updatedOperation).Inc()
+	}
+	conditions.MarkTrue(cluster, ArgoCDRegisteredCondition)
+	setRegistered(cluster, true, "")
+	setKubeconfigExpiry(cluster, config)
+
+	// Remove the secrets left behind by a change of the secret name.
+	secrets, err := c.listArgoClusterSecrets(ctx, cluster.Namespace, cluster.Name)
+	if err != nil {
+		return err
+	}
+	for i := range secrets {
+		if secrets[i].Name == newArgoClusterSecret.Name {
+			continue
+		}
+		if err := c.deleteArgoClusterSecret(ctx, &secrets[i]); err != nil {
+			return err
+		}
+	}
+
+	// Replace the adopted secrets registering the same API server under
+	// another name. Their project, namespaces and labels are not carried
+	// over, so the replacement is reported in an Event.
+	for i := range unmanaged {
+		if unmanaged[i].Name == newArgoClusterSecret.Name {
+			continue
+		}
+		if err := c.deleteArgoClusterSecret(ctx, &unmanaged[i]); err != nil {
+			return err
+		}
+		c.event(cluster, corev1.EventTypeWarning, ArgoCDClusterAdoptedReason,
+			"Replaced ArgoCD cluster secret %s/%s created by hand with %s/%s",
+			unmanaged[i].Namespace, unmanaged[i].Name, newArgoClusterSecret.Namespace, newArgoClusterSecret.Name,
+		)
+	}
+
+	return nil
+}
+
+// upgradeManagedFields moves the fields of the secret managed by capargo
+// through Create and Update operations to its apply field manager.
+func (c *ClusterKubeconfigReconciler) upgradeManagedFields(ctx context.Context, secret *corev1.Secret) error {
+	jsonPatch, err := csaupgrade.UpgradeManagedFieldsPatch(secret,
+		sets.New(common.ControllerName), common.ControllerName,
+	)
+	if err != nil {
+		return err
+	}
+	if jsonPatch == nil {
+		return nil
+	}
+	logger.V(4).Info("Upgrading the managed fields of the ArgoCD cluster secret",
+		"secret namespace", secret.GetNamespace(), "secret name", secret.GetName(),
+	)
+	return c.Patch(ctx, secret, client.RawPatch(apimachinerytypes.JSONPatchType, jsonPatch))
+}
+
+// setServer overrides the API server address of the kubeconfig with the
+// in-cluster address of the control plane, when enabled, and with the server
+// annotation of the cluster, in increasing order of precedence. The TLS
+// server name is set to the original host, so that the certificate of the
+// API server can still be verified, unless it is set by the TLS server name
+// annotation.
+func (c *ClusterKubeconfigReconciler) setServer(ctx context.Context, cluster *capiv1beta1.Cluster, config *rest.Config) error {
+	host := config.Host
+	if c.InClusterServer {
+		server, err := c.GetCapiInClusterServer(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		if server != "" {
+			config.Host = server
+		}
+	}
+	if server, ok := cluster.Annotations[common.ServerAnnotation]; ok {
+		config.Host = server
+	}
+	if config.Host != host && config.TLSClientConfig.ServerName == "" {
+		config.TLSClientConfig.ServerName = hostname(host)
+	}
+	if serverName, ok := cluster.Annotations[common.TLSServerNameAnnotation]; ok {
+		config.TLSClientConfig.ServerName = serverName
+	}
+	return nil
+}
+
+// hostname returns the host name of a server address, with or without
+// scheme and port.
+func hostname(server string) string {
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	u, err := url.Parse(server)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// argoProject returns the ArgoCD project of the cluster, from its project
+// annotation or else from the project of its namespace. An empty project
+// makes the cluster available to every project.
+func (c *ClusterKubeconfigReconciler) argoProject(cluster *capiv1beta1.Cluster) string {
+	if project, ok := cluster.Annotations[common.ProjectAnnotation]; ok {
+		return project
+	}
+	return c.NamespaceProjects[cluster.Namespace]
+}
 
+// setArgoScope restricts the ArgoCD cluster to the namespaces listed in the
+// namespaces annotation of the cluster, and optionally to cluster-scoped
+// resources, by setting the corresponding fields of the secret data.
+func setArgoScope(cluster *capiv1beta1.Cluster, data map[string][]byte) error {
+	var namespaces []string
+	for _, ns := range strings.Split(cluster.Annotations[common.ArgoNamespacesAnnotation], ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	if len(namespaces) > 0 {
+		data["namespaces"] = []byte(strings.Join(namespaces, ","))
+	}
+	if v, ok := cluster.Annotations[common.ArgoClusterResourcesAnnotation]; ok {
+		clusterResources, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s annotation on cluster %s/%s: %v",
+				common.ArgoClusterResourcesAnnotation, cluster.Namespace, cluster.Name, err,
+			)
+		}
+		data["clusterResources"] = []byte(strconv.FormatBool(clusterResources))
+	}
+	return nil
+}
+
+// setAWSAuthConfig overrides the EKS cluster name and role ARN found in the
+// kubeconfig with the ones from the control plane and the Cluster
+// annotations, in increasing order of precedence.
+func (c *ClusterKubeconfigReconciler) setAWSAuthConfig(ctx context.Context, cluster *capiv1beta1.Cluster, awsAuthConfig *argocdv1alpha1.AWSAuthConfig) error {
+	name, err := c.GetCapiAWSClusterName(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		awsAuthConfig.ClusterName = name
+	}
+	if name, ok := cluster.Annotations[common.AWSClusterNameAnnotation]; ok {
+		awsAuthConfig.ClusterName = name
+	}
+	if roleARN, ok := cluster.Annotations[common.AWSRoleARNAnnotation]; ok {
+		awsAuthConfig.RoleARN = roleARN
+	}
 	return nil
 }
 
@@ -186,17 +853,19 @@ func buildClusterConfigFromRestConfig(config *rest.Config) argocdv1alpha1.Cluste
 
 	cc.TLSClientConfig = tlsClientConfig
 
-	// TODO: AWS Auth Config
-
 	if config.ExecProvider != nil {
-		execProviderConfig := &argocdv1alpha1.ExecProviderConfig{
-			Command:     config.ExecProvider.Command,
-			Args:        config.ExecProvider.Args,
-			Env:         mapEnv(config.ExecProvider.Env),
-			APIVersion:  config.ExecProvider.APIVersion,
-			InstallHint: config.ExecProvider.InstallHint,
-		}
-		cc.ExecProviderConfig = execProviderConfig
+		// ArgoCD images ship neither the aws CLI nor aws-iam-authenticator,
+		// but can authenticate against EKS by themselves.
+		if awsAuthConfig := buildAWSAuthConfig(config.ExecProvider); awsAuthConfig != nil {
+			cc.AWSAuthConfig = awsAuthConfig
+			return cc
+		}
+		// kubelogin kubeconfigs that also carry static credentials (e.g.
+		// AKS clusters with local accounts enabled) work without it.
+		if filepath.Base(config.ExecProvider.Command) == kubeloginCommand && hasStaticCredentials(config) {
+			return cc
+		}
+		cc.ExecProviderConfig = buildExecProviderConfig(config.ExecProvider)
 	}
 	return cc
 }
@@ -8,8 +8,13 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/superorbital/capargo/pkg/common"
+	"github.com/superorbital/capargo/pkg/providers"
 	"github.com/superorbital/capargo/pkg/types"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -125,6 +130,7 @@ var _ = Describe("Cluster API to ArgoCD cluster controller", Ordered, func() {
 			Expect(k8sClient.Create(ctx, &vcluster, &client.CreateOptions{})).To(Succeed())
 
 			By("calling the reconcile function")
+			recorder := record.NewFakeRecorder(10)
 			reconciler := &ClusterKubeconfigReconciler{
 				Client: k8sClient,
 				Options: types.Options{
@@ -133,6 +139,7 @@ var _ = Describe("Cluster API to ArgoCD cluster controller", Ordered, func() {
 					ArgoNamespace:    argoNamespace,
 					Timeout:          5 * time.Minute,
 				},
+				Recorder: recorder,
 			}
 
 			By("ensuring that the reconcile is requeued if the control plane is not ready")
@@ -147,6 +154,8 @@ var _ = Describe("Cluster API to ArgoCD cluster controller", Ordered, func() {
 			)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&vcluster), &vcluster, &client.GetOptions{})).To(Succeed())
+			Expect(conditions.GetReason(&vcluster, ArgoCDRegisteredCondition)).To(Equal(WaitingForControlPlaneReason))
 
 			By("creating a kubeconfig secret")
 			kubeconfig := corev1.Secret{
@@ -182,8 +191,64 @@ var _ = Describe("Cluster API to ArgoCD cluster controller", Ordered, func() {
 			}
 			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).NotTo(HaveOccurred())
 			Expect(secret.Labels[argocdcommon.LabelKeySecretType]).To(Equal(argocdcommon.LabelValueSecretTypeCluster))
+			Expect(secret.Labels[common.ClusterIDLabel]).To(Equal("envTest"))
 			Expect(secret.Data).NotTo(BeEmpty())
 			Expect(secret.Data["server"]).To(Equal([]byte("https://vcluster-1.vcluster.svc:443")))
+
+			By("checking that the registration is reported on the cluster")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&vcluster), &vcluster, &client.GetOptions{})).To(Succeed())
+			Expect(conditions.IsTrue(&vcluster, ArgoCDRegisteredCondition)).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring(ArgoCDClusterCreatedReason)))
+		})
+
+		It("should report clusters with an unsupported control plane", func() {
+			clusterName := "test-unsupported"
+			By("creating a cluster object with an unknown control plane reference")
+			cluster := capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName,
+					Namespace: testNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Kind:       "UnknownControlPlane",
+						Namespace:  testNamespace,
+						Name:       clusterName,
+						APIVersion: "controlplane.example.com/v1alpha1",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &cluster, &client.CreateOptions{})).To(Succeed())
+			cluster.Status = capiv1beta1.ClusterStatus{
+				ControlPlaneReady: true,
+			}
+			Expect(k8sClient.Status().Update(ctx, &cluster, &client.SubResourceUpdateOptions{})).To(Succeed())
+
+			By("calling the reconcile function")
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &ClusterKubeconfigReconciler{
+				Client: k8sClient,
+				Options: types.Options{
+					ClusterID:        "envTest",
+					ClusterNamespace: testNamespace,
+					ArgoNamespace:    argoNamespace,
+					Timeout:          5 * time.Minute,
+				},
+				ClusterProvider: providers.ClusterProvider{
+					Client: k8sClient,
+				},
+				Recorder: recorder,
+			}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(&cluster),
+			})
+			Expect(err).To(HaveOccurred())
+
+			By("checking that the failure is reported on the cluster")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&cluster), &cluster, &client.GetOptions{})).To(Succeed())
+			Expect(conditions.IsFalse(&cluster, ArgoCDRegisteredCondition)).To(BeTrue())
+			Expect(conditions.GetReason(&cluster, ArgoCDRegisteredCondition)).To(Equal(UnsupportedProviderReason))
+			Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " " + UnsupportedProviderReason)))
 		})
 
 		It("should update an ArgoCD cluster secret for a VCluster", func() {
@@ -272,6 +337,10 @@ var _ = Describe("Cluster API to ArgoCD cluster controller", Ordered, func() {
 			Expect(secret.Data).NotTo(BeEmpty())
 			Expect(secret.Data["server"]).To(Equal([]byte("https://vcluster-1.vcluster.svc:443")))
 
+			By("labelling the ArgoCD cluster secret by hand")
+			secret.Labels["example.com/team"] = "platform"
+			Expect(k8sClient.Update(ctx, &secret, &client.UpdateOptions{})).To(Succeed())
+
 			By("updating the kubeconfig secret")
 			kubeconfig.StringData = map[string]string{
 				"value": vclusterKubeconfig6443,
@@ -295,6 +364,91 @@ var _ = Describe("Cluster API to ArgoCD cluster controller", Ordered, func() {
 			Expect(secret.Labels[argocdcommon.LabelKeySecretType]).To(Equal(argocdcommon.LabelValueSecretTypeCluster))
 			Expect(secret.Data).NotTo(BeEmpty())
 			Expect(secret.Data["server"]).To(Equal([]byte("https://vcluster-1.vcluster.svc:6443")))
+			Expect(secret.Labels["example.com/team"]).To(Equal("platform"))
+		})
+
+		It("should prune the fields of ArgoCD cluster secrets written by older versions", func() {
+			vclusterName := "test-vcluster"
+			By("creating a ready cluster object with a VCluster control plane reference")
+			vcluster := capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName,
+					Namespace: testNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Kind:       "VCluster",
+						Namespace:  testNamespace,
+						Name:       vclusterName,
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha1",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &vcluster, &client.CreateOptions{})).To(Succeed())
+			kubeconfig := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName + "-kubeconfig",
+					Namespace: testNamespace,
+				},
+				StringData: map[string]string{
+					"value": vclusterKubeconfig443,
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig, &client.CreateOptions{})).To(Succeed())
+			vcluster.Status = capiv1beta1.ClusterStatus{
+				ControlPlaneReady: true,
+			}
+			Expect(k8sClient.Status().Update(ctx, &vcluster, &client.SubResourceUpdateOptions{})).To(Succeed())
+
+			By("creating the ArgoCD cluster secret like older versions of capargo")
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testNamespace + "-" + vclusterName,
+					Namespace: argoNamespace,
+					Labels: map[string]string{
+						argocdcommon.LabelKeySecretType: argocdcommon.LabelValueSecretTypeCluster,
+						common.ControllerNameLabel:      common.ControllerName,
+						common.ClusterIDLabel:           "envTest",
+						"example.com/team":              "platform",
+					},
+					Annotations: map[string]string{
+						common.ClusterNameAnnotation:      vclusterName,
+						common.ClusterNamespaceAnnotation: testNamespace,
+					},
+				},
+				StringData: map[string]string{
+					"name":    vclusterName,
+					"server":  "https://vcluster-1.vcluster.svc:443",
+					"project": "platform",
+				},
+			}
+			Expect(k8sClient.Create(ctx, &secret, client.FieldOwner(common.ControllerName))).To(Succeed())
+
+			By("calling the reconcile function")
+			reconciler := &ClusterKubeconfigReconciler{
+				Client: k8sClient,
+				Options: types.Options{
+					ClusterID:        "envTest",
+					ClusterNamespace: testNamespace,
+					ArgoNamespace:    argoNamespace,
+					Timeout:          5 * time.Minute,
+				},
+			}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(&vcluster),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking that the fields capargo does not set anymore are removed")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+			Expect(secret.Labels).NotTo(HaveKey("example.com/team"))
+			Expect(secret.Data).NotTo(HaveKey("project"))
+			Expect(secret.Labels).To(HaveKeyWithValue(common.ClusterIDLabel, "envTest"))
+			for _, entry := range secret.ManagedFields {
+				if entry.Manager == common.ControllerName {
+					Expect(entry.Operation).To(Equal(metav1.ManagedFieldsOperationApply))
+				}
+			}
 		})
 
 		It("should remove an ArgoCD cluster secret when the VCluster is deleted", func() {
@@ -404,5 +558,586 @@ var _ = Describe("Cluster API to ArgoCD cluster controller", Ordered, func() {
 			Expect(err).To(HaveOccurred())
 			Expect(errors.IsNotFound(err)).To(BeTrue())
 		})
+
+		It("should not touch the ArgoCD cluster secret of another capargo instance", func() {
+			vclusterName := "test-vcluster"
+			By("creating an ArgoCD cluster secret with another ID")
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testNamespace + "-" + vclusterName,
+					Namespace: argoNamespace,
+					Annotations: map[string]string{
+						common.ClusterNameAnnotation:      vclusterName,
+						common.ClusterNamespaceAnnotation: testNamespace,
+					},
+					Labels: map[string]string{
+						argocdcommon.LabelKeySecretType: argocdcommon.LabelValueSecretTypeCluster,
+						common.ControllerNameLabel:      common.ControllerName,
+						common.ClusterIDLabel:           "other",
+					},
+				},
+				StringData: map[string]string{
+					"server": "https://other.example.com",
+				},
+			}
+			Expect(k8sClient.Create(ctx, &secret, &client.CreateOptions{})).To(Succeed())
+
+			By("creating a ready cluster object with a VCluster control plane reference")
+			vcluster := capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName,
+					Namespace: testNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Kind:       "VCluster",
+						Namespace:  testNamespace,
+						Name:       vclusterName,
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha1",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &vcluster, &client.CreateOptions{})).To(Succeed())
+			kubeconfig := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName + "-kubeconfig",
+					Namespace: testNamespace,
+				},
+				StringData: map[string]string{
+					"value": vclusterKubeconfig443,
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig, &client.CreateOptions{})).To(Succeed())
+			vcluster.Status = capiv1beta1.ClusterStatus{
+				ControlPlaneReady: true,
+			}
+			Expect(k8sClient.Status().Update(ctx, &vcluster, &client.SubResourceUpdateOptions{})).To(Succeed())
+
+			reconciler := &ClusterKubeconfigReconciler{
+				Client: k8sClient,
+				Options: types.Options{
+					ClusterID:        "envTest",
+					ClusterNamespace: testNamespace,
+					ArgoNamespace:    argoNamespace,
+					Timeout:          5 * time.Minute,
+				},
+			}
+			request := reconcile.Request{
+				NamespacedName: apimachinerytypes.NamespacedName{
+					Namespace: testNamespace,
+					Name:      vclusterName,
+				},
+			}
+
+			By("checking that the ArgoCD cluster secret is not updated")
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).To(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+			Expect(secret.Data["server"]).To(Equal([]byte("https://other.example.com")))
+
+			By("checking that the ArgoCD cluster secret is not removed when the cluster is deleted")
+			Expect(k8sClient.Delete(ctx, &kubeconfig, &client.DeleteOptions{})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &vcluster, &client.DeleteOptions{})).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+		})
+
+		It("should only take over the unlabelled ArgoCD cluster secrets of its own clusters", func() {
+			vclusterName := "test-vcluster"
+			By("creating an ArgoCD cluster secret registered by an older version")
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testNamespace + "-" + vclusterName,
+					Namespace: argoNamespace,
+					Annotations: map[string]string{
+						common.ClusterNameAnnotation:      vclusterName,
+						common.ClusterNamespaceAnnotation: testNamespace,
+					},
+					Labels: map[string]string{
+						argocdcommon.LabelKeySecretType: argocdcommon.LabelValueSecretTypeCluster,
+						common.ControllerNameLabel:      common.ControllerName,
+					},
+				},
+				StringData: map[string]string{
+					"server": "https://other.example.com",
+				},
+			}
+			Expect(k8sClient.Create(ctx, &secret, &client.CreateOptions{})).To(Succeed())
+
+			reconciler := &ClusterKubeconfigReconciler{
+				Client: k8sClient,
+				Options: types.Options{
+					ClusterID:        "envTest",
+					ClusterNamespace: testNamespace,
+					ArgoNamespace:    argoNamespace,
+					Timeout:          5 * time.Minute,
+				},
+			}
+			request := reconcile.Request{
+				NamespacedName: apimachinerytypes.NamespacedName{
+					Namespace: testNamespace,
+					Name:      vclusterName,
+				},
+			}
+
+			By("checking that the secret is not removed while its cluster does not exist here")
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+			Expect(secret.Labels).NotTo(HaveKey(common.ClusterIDLabel))
+
+			By("creating a ready cluster object with a VCluster control plane reference")
+			vcluster := capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName,
+					Namespace: testNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Kind:       "VCluster",
+						Namespace:  testNamespace,
+						Name:       vclusterName,
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha1",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &vcluster, &client.CreateOptions{})).To(Succeed())
+			kubeconfig := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName + "-kubeconfig",
+					Namespace: testNamespace,
+				},
+				StringData: map[string]string{
+					"value": vclusterKubeconfig443,
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig, &client.CreateOptions{})).To(Succeed())
+			vcluster.Status = capiv1beta1.ClusterStatus{
+				ControlPlaneReady: true,
+			}
+			Expect(k8sClient.Status().Update(ctx, &vcluster, &client.SubResourceUpdateOptions{})).To(Succeed())
+
+			By("checking that the secret is taken over once its cluster exists")
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+			Expect(secret.Labels).To(HaveKeyWithValue(common.ClusterIDLabel, "envTest"))
+			Expect(secret.Data["server"]).To(Equal([]byte("https://vcluster-1.vcluster.svc:443")))
+		})
+
+		It("should keep the ArgoCD cluster secret of a paused cluster", func() {
+			vclusterName := "test-vcluster"
+			By("creating a ready cluster object with a VCluster control plane reference")
+			vcluster := capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName,
+					Namespace: testNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Kind:       "VCluster",
+						Namespace:  testNamespace,
+						Name:       vclusterName,
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha1",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &vcluster, &client.CreateOptions{})).To(Succeed())
+			kubeconfig := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName + "-kubeconfig",
+					Namespace: testNamespace,
+				},
+				StringData: map[string]string{
+					"value": vclusterKubeconfig443,
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig, &client.CreateOptions{})).To(Succeed())
+			vcluster.Status = capiv1beta1.ClusterStatus{
+				ControlPlaneReady: true,
+			}
+			Expect(k8sClient.Status().Update(ctx, &vcluster, &client.SubResourceUpdateOptions{})).To(Succeed())
+
+			reconciler := &ClusterKubeconfigReconciler{
+				Client: k8sClient,
+				Options: types.Options{
+					ClusterID:        "envTest",
+					ClusterNamespace: testNamespace,
+					ArgoNamespace:    argoNamespace,
+					Timeout:          5 * time.Minute,
+				},
+			}
+			request := reconcile.Request{
+				NamespacedName: apimachinerytypes.NamespacedName{
+					Namespace: testNamespace,
+					Name:      vclusterName,
+				},
+			}
+
+			By("checking that the ArgoCD cluster secret is created")
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testNamespace + "-" + vclusterName,
+					Namespace: argoNamespace,
+				},
+			}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+
+			By("pausing the cluster")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&vcluster), &vcluster, &client.GetOptions{})).To(Succeed())
+			vcluster.Spec.Paused = true
+			Expect(k8sClient.Update(ctx, &vcluster, &client.UpdateOptions{})).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+			Expect(secret.Annotations).To(HaveKeyWithValue(common.PausedAnnotation, "true"))
+
+			By("deleting the paused cluster like clusterctl move, which removes its finalizers")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&vcluster), &vcluster, &client.GetOptions{})).To(Succeed())
+			vcluster.Finalizers = nil
+			Expect(k8sClient.Update(ctx, &vcluster, &client.UpdateOptions{})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &kubeconfig, &client.DeleteOptions{})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &vcluster, &client.DeleteOptions{})).To(Succeed())
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&vcluster), &vcluster, &client.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("checking that the ArgoCD cluster secret is kept after a restart of capargo")
+			restarted := &ClusterKubeconfigReconciler{
+				Client:  k8sClient,
+				Options: reconciler.Options,
+			}
+			_, err = restarted.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+
+			By("checking that the garbage collection keeps the ArgoCD cluster secret")
+			orphaned, err := (&GarbageCollector{ClusterKubeconfigReconciler: restarted}).isOrphaned(ctx, &secret)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphaned).To(BeFalse())
+
+			By("moving the cluster to a management cluster with another ID")
+			moved := &ClusterKubeconfigReconciler{
+				Client:  k8sClient,
+				Options: reconciler.Options,
+			}
+			moved.ClusterID = "moved"
+			vcluster = capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName,
+					Namespace: testNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Kind:       "VCluster",
+						Namespace:  testNamespace,
+						Name:       vclusterName,
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha1",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &vcluster, &client.CreateOptions{})).To(Succeed())
+			kubeconfig = corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName + "-kubeconfig",
+					Namespace: testNamespace,
+				},
+				StringData: map[string]string{
+					"value": vclusterKubeconfig443,
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig, &client.CreateOptions{})).To(Succeed())
+			vcluster.Status = capiv1beta1.ClusterStatus{
+				ControlPlaneReady: true,
+			}
+			Expect(k8sClient.Status().Update(ctx, &vcluster, &client.SubResourceUpdateOptions{})).To(Succeed())
+
+			By("checking that the ArgoCD cluster secret is taken over by the other instance")
+			_, err = moved.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+			Expect(secret.Labels).To(HaveKeyWithValue(common.ClusterIDLabel, "moved"))
+			Expect(secret.Annotations).NotTo(HaveKey(common.PausedAnnotation))
+		})
+
+		It("should wait for the ArgoCD applications before removing the ArgoCD cluster secret", func() {
+			vclusterName := "test-vcluster"
+			By("creating a ready cluster object with a VCluster control plane reference")
+			vcluster := capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName,
+					Namespace: testNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Kind:       "VCluster",
+						Namespace:  testNamespace,
+						Name:       vclusterName,
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha1",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &vcluster, &client.CreateOptions{})).To(Succeed())
+			kubeconfig := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName + "-kubeconfig",
+					Namespace: testNamespace,
+				},
+				StringData: map[string]string{
+					"value": vclusterKubeconfig443,
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig, &client.CreateOptions{})).To(Succeed())
+			vcluster.Status = capiv1beta1.ClusterStatus{
+				ControlPlaneReady: true,
+			}
+			Expect(k8sClient.Status().Update(ctx, &vcluster, &client.SubResourceUpdateOptions{})).To(Succeed())
+
+			reconciler := &ClusterKubeconfigReconciler{
+				Client: k8sClient,
+				Options: types.Options{
+					ClusterID:           "envTest",
+					ClusterNamespace:    testNamespace,
+					ArgoNamespace:       argoNamespace,
+					Timeout:             5 * time.Minute,
+					WaitForApplications: true,
+				},
+			}
+			request := reconcile.Request{
+				NamespacedName: apimachinerytypes.NamespacedName{
+					Namespace: testNamespace,
+					Name:      vclusterName,
+				},
+			}
+
+			By("checking that the ArgoCD cluster secret is created along with the finalizer")
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testNamespace + "-" + vclusterName,
+					Namespace: argoNamespace,
+				},
+			}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&vcluster), &vcluster, &client.GetOptions{})).To(Succeed())
+			Expect(vcluster.Finalizers).To(ContainElement(common.ClusterFinalizer))
+
+			By("creating an ArgoCD application targeting the cluster")
+			application := unstructured.Unstructured{}
+			application.SetAPIVersion("argoproj.io/v1alpha1")
+			application.SetKind("Application")
+			application.SetName(testNamespace)
+			application.SetNamespace(argoNamespace)
+			Expect(unstructured.SetNestedField(application.Object, "https://vcluster-1.vcluster.svc:443", "spec", "destination", "server")).To(Succeed())
+			Expect(k8sClient.Create(ctx, &application, &client.CreateOptions{})).To(Succeed())
+
+			By("checking that the ArgoCD cluster secret is kept while the application exists")
+			Expect(k8sClient.Delete(ctx, &kubeconfig, &client.DeleteOptions{})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &vcluster, &client.DeleteOptions{})).To(Succeed())
+			result, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+
+			By("checking that the ArgoCD cluster secret and the cluster are removed once the application is")
+			Expect(k8sClient.Delete(ctx, &application, &client.DeleteOptions{})).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&vcluster), &vcluster, &client.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should not let two clusters share an ArgoCD cluster secret", func() {
+			clusterName := "prod-" + uuid.New().String()[:8]
+			otherNamespace := testNamespace + "-other"
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: otherNamespace},
+			}, &client.CreateOptions{})).To(Succeed())
+
+			By("creating two ready clusters with the same name in different namespaces")
+			for _, namespace := range []string{testNamespace, otherNamespace} {
+				cluster := capiv1beta1.Cluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      clusterName,
+						Namespace: namespace,
+					},
+					Spec: capiv1beta1.ClusterSpec{
+						ControlPlaneRef: &corev1.ObjectReference{
+							Kind:       "VCluster",
+							Namespace:  namespace,
+							Name:       clusterName,
+							APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha1",
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, &cluster, &client.CreateOptions{})).To(Succeed())
+				kubeconfig := corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      clusterName + "-kubeconfig",
+						Namespace: namespace,
+					},
+					StringData: map[string]string{
+						"value": vclusterKubeconfig443,
+					},
+				}
+				Expect(k8sClient.Create(ctx, &kubeconfig, &client.CreateOptions{})).To(Succeed())
+				cluster.Status = capiv1beta1.ClusterStatus{
+					ControlPlaneReady: true,
+				}
+				Expect(k8sClient.Status().Update(ctx, &cluster, &client.SubResourceUpdateOptions{})).To(Succeed())
+			}
+
+			reconciler := &ClusterKubeconfigReconciler{
+				Client: k8sClient,
+				Options: types.Options{
+					ClusterID:          "envTest",
+					ClusterNamespace:   testNamespace + "," + otherNamespace,
+					ArgoNamespace:      argoNamespace,
+					Timeout:            5 * time.Minute,
+					SecretNameTemplate: "{{ .Cluster.Name }}",
+				},
+			}
+
+			By("registering the first cluster")
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: apimachinerytypes.NamespacedName{Namespace: testNamespace, Name: clusterName},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking that the second cluster cannot take the secret over")
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: apimachinerytypes.NamespacedName{Namespace: otherNamespace, Name: clusterName},
+			})
+			Expect(err).To(MatchError(ContainSubstring("already registers cluster " + testNamespace + "/" + clusterName)))
+			secret := corev1.Secret{}
+			Expect(k8sClient.Get(ctx, apimachinerytypes.NamespacedName{Namespace: argoNamespace, Name: clusterName}, &secret, &client.GetOptions{})).To(Succeed())
+			Expect(secret.Annotations[common.ClusterNamespaceAnnotation]).To(Equal(testNamespace))
+		})
+
+		It("should apply the adoption policy to ArgoCD cluster secrets created by hand", func() {
+			vclusterName := "test-vcluster"
+			By("creating an ArgoCD cluster secret for the same server by hand")
+			manual := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testNamespace + "-manual",
+					Namespace: argoNamespace,
+					Labels: map[string]string{
+						argocdcommon.LabelKeySecretType: argocdcommon.LabelValueSecretTypeCluster,
+					},
+				},
+				StringData: map[string]string{
+					"name":   "manual",
+					"server": "https://vcluster-1.vcluster.svc:443/",
+				},
+			}
+			Expect(k8sClient.Create(ctx, &manual, &client.CreateOptions{})).To(Succeed())
+
+			By("creating a ready cluster object with a VCluster control plane reference")
+			vcluster := capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName,
+					Namespace: testNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Kind:       "VCluster",
+						Namespace:  testNamespace,
+						Name:       vclusterName,
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha1",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &vcluster, &client.CreateOptions{})).To(Succeed())
+			kubeconfig := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vclusterName + "-kubeconfig",
+					Namespace: testNamespace,
+				},
+				StringData: map[string]string{
+					"value": vclusterKubeconfig443,
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig, &client.CreateOptions{})).To(Succeed())
+			vcluster.Status = capiv1beta1.ClusterStatus{
+				ControlPlaneReady: true,
+			}
+			Expect(k8sClient.Status().Update(ctx, &vcluster, &client.SubResourceUpdateOptions{})).To(Succeed())
+
+			recorder := record.NewFakeRecorder(20)
+			reconciler := &ClusterKubeconfigReconciler{
+				Client: k8sClient,
+				Options: types.Options{
+					ClusterID:        "envTest",
+					ClusterNamespace: testNamespace,
+					ArgoNamespace:    argoNamespace,
+					Timeout:          5 * time.Minute,
+				},
+				Recorder: recorder,
+			}
+			request := reconcile.Request{
+				NamespacedName: apimachinerytypes.NamespacedName{
+					Namespace: testNamespace,
+					Name:      vclusterName,
+				},
+			}
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testNamespace + "-" + vclusterName,
+					Namespace: argoNamespace,
+				},
+			}
+
+			By("checking that the registration fails with the fail policy")
+			reconciler.AdoptionPolicy = types.AdoptionPolicyFail
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).To(HaveOccurred())
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("checking that the cluster is not registered with the skip policy")
+			reconciler.AdoptionPolicy = types.AdoptionPolicySkip
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&manual), &manual, &client.GetOptions{})).To(Succeed())
+
+			By("checking that the secret is replaced with the adopt policy")
+			reconciler.AdoptionPolicy = types.AdoptionPolicyAdopt
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})).To(Succeed())
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&manual), &manual, &client.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Eventually(recorder.Events).Should(Receive(ContainSubstring(ArgoCDClusterAdoptedReason)))
+
+			By("checking that the skip policy defers to a secret created by hand later")
+			later := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testNamespace + "-later",
+					Namespace: argoNamespace,
+					Labels: map[string]string{
+						argocdcommon.LabelKeySecretType: argocdcommon.LabelValueSecretTypeCluster,
+					},
+				},
+				StringData: map[string]string{
+					"name":   "later",
+					"server": "https://vcluster-1.vcluster.svc:443",
+				},
+			}
+			Expect(k8sClient.Create(ctx, &later, &client.CreateOptions{})).To(Succeed())
+			reconciler.AdoptionPolicy = types.AdoptionPolicySkip
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret, &client.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&later), &later, &client.GetOptions{})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &later, &client.DeleteOptions{})).To(Succeed())
+		})
 	})
 })
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/superorbital/capargo/pkg/providers"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+
+	corev1 "k8s.io/api/core/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// ArgoCDRegisteredCondition reports whether the cluster is registered in
+// ArgoCD.
+const ArgoCDRegisteredCondition capiv1beta1.ConditionType = "ArgoCDRegistered"
+
+// Reasons of the Events emitted on clusters, and of their ArgoCDRegistered
+// condition.
+const (
+	ArgoCDClusterCreatedReason   = "ArgoCDClusterCreated"
+	ArgoCDClusterUpdatedReason   = "ArgoCDClusterUpdated"
+	ArgoCDClusterDeletedReason   = "ArgoCDClusterDeleted"
+	ArgoCDClusterAdoptedReason   = "ArgoCDClusterAdopted"
+	ArgoCDClusterMovedReason     = "ArgoCDClusterMoved"
+	WaitingForControlPlaneReason = "WaitingForControlPlane"
+	NotSelectedReason            = "NotSelected"
+	AlreadyRegisteredReason      = "AlreadyRegistered"
+	SecretNameConflictReason     = "SecretNameConflict"
+	UnsupportedProviderReason    = "UnsupportedProvider"
+	KubeconfigNotFoundReason     = "KubeconfigNotFound"
+	InvalidKubeconfigReason      = "InvalidKubeconfig"
+	MissingKubeconfigKeyReason   = "MissingKubeconfigKey"
+	RegistrationFailedReason     = "RegistrationFailed"
+)
+
+// registrationError is an error of the registration of a cluster, along with
+// the reason reported in its Events and ArgoCDRegistered condition.
+type registrationError struct {
+	reason string
+	err    error
+}
+
+func (e *registrationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *registrationError) Unwrap() error {
+	return e.err
+}
+
+// providerError wraps an error of the cluster provider, reporting the
+// unsupported control planes.
+func providerError(err error) error {
+	if errors.Is(err, providers.ErrUnsupported) {
+		return &registrationError{reason: UnsupportedProviderReason, err: err}
+	}
+	return err
+}
+
+// event emits an Event on the object, if an EventRecorder is set.
+func (c *ClusterKubeconfigReconciler) event(object runtime.Object, eventType, reason, messageFormat string, args ...interface{}) {
+	if c.Recorder == nil {
+		return
+	}
+	c.Recorder.Eventf(object, eventType, reason, messageFormat, args...)
+}
+
+// markRegistrationFailed reports the failed registration of the cluster in a
+// warning Event and in its ArgoCDRegistered condition.
+func (c *ClusterKubeconfigReconciler) markRegistrationFailed(cluster *capiv1beta1.Cluster, err error) {
+	reason := RegistrationFailedReason
+	var rerr *registrationError
+	if errors.As(err, &rerr) {
+		reason = rerr.reason
+	}
+	c.event(cluster, corev1.EventTypeWarning, reason, "Could not register cluster in ArgoCD: %v", err)
+	registrationFailures.WithLabelValues(reason).Inc()
+	setRegistered(cluster, false, reason)
+	conditions.MarkFalse(cluster, ArgoCDRegisteredCondition, reason, capiv1beta1.ConditionSeverityError, "%v", err)
+}
+
+// patchConditions patches the ArgoCDRegistered condition of the cluster.
+func (c *ClusterKubeconfigReconciler) patchConditions(ctx context.Context, patchHelper *patch.Helper, cluster *capiv1beta1.Cluster) error {
+	if err := patchHelper.Patch(ctx, cluster, patch.WithOwnedConditions{
+		Conditions: []capiv1beta1.ConditionType{ArgoCDRegisteredCondition},
+	}); err != nil {
+		return fmt.Errorf("could not patch the conditions of cluster %s/%s: %v",
+			cluster.Namespace, cluster.Name, err,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"path/filepath"
+	"strings"
+
+	"k8s.io/client-go/rest"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// argocdK8sAuthCommand is the credential plugin shipped in the ArgoCD images,
+// which can retrieve tokens for the managed Kubernetes offerings of the
+// major cloud providers.
+const argocdK8sAuthCommand = "argocd-k8s-auth"
+
+// execCredentialAPIVersion is the exec credential API version spoken by
+// argocd-k8s-auth.
+const execCredentialAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+const (
+	kubeloginCommand           = "kubelogin"
+	gkeGcloudAuthPluginCommand = "gke-gcloud-auth-plugin"
+	awsCommand                 = "aws"
+	awsIAMAuthenticatorCommand = "aws-iam-authenticator"
+)
+
+// kubeloginEnv maps the kubelogin get-token flags onto the environment
+// variables read by "argocd-k8s-auth azure". It only authenticates through
+// the workload identity of ArgoCD, so the identities and credentials of the
+// kubeconfig, e.g. --client-id or --client-secret, are left out.
+var kubeloginEnv = map[string]string{
+	"server-id":      "AAD_SERVER_APPLICATION_ID",
+	"environment":    "AAD_ENVIRONMENT_NAME",
+	"e":              "AAD_ENVIRONMENT_NAME",
+	"authority-host": "AZURE_AUTHORITY_HOST",
+}
+
+// buildExecProviderConfig converts the exec credential plugin of a
+// kubeconfig into an ArgoCD exec provider. Plugins that are not available in
+// the ArgoCD images are replaced by argocd-k8s-auth when possible, and
+// copied verbatim otherwise.
+func buildExecProviderConfig(exec *clientcmdapi.ExecConfig) *argocdv1alpha1.ExecProviderConfig {
+	switch filepath.Base(exec.Command) {
+	case kubeloginCommand:
+		return buildKubeloginExecProviderConfig(exec)
+	case gkeGcloudAuthPluginCommand:
+		return buildGKEExecProviderConfig(exec)
+	default:
+		return &argocdv1alpha1.ExecProviderConfig{
+			Command:     exec.Command,
+			Args:        exec.Args,
+			Env:         mapEnv(exec.Env),
+			APIVersion:  exec.APIVersion,
+			InstallHint: exec.InstallHint,
+		}
+	}
+}
+
+// buildKubeloginExecProviderConfig converts a "kubelogin get-token" exec
+// plugin into "argocd-k8s-auth azure", which authenticates with the workload
+// identity of ArgoCD. Only the variables read by argocd-k8s-auth are kept.
+func buildKubeloginExecProviderConfig(exec *clientcmdapi.ExecConfig) *argocdv1alpha1.ExecProviderConfig {
+	env := map[string]string{}
+	for _, e := range exec.Env {
+		for _, name := range kubeloginEnv {
+			if e.Name == name {
+				env[name] = e.Value
+			}
+		}
+	}
+	for flag, value := range parseFlags(exec.Args) {
+		if name, ok := kubeloginEnv[flag]; ok {
+			env[name] = value
+		}
+	}
+	return &argocdv1alpha1.ExecProviderConfig{
+		Command:    argocdK8sAuthCommand,
+		Args:       []string{"azure"},
+		Env:        env,
+		APIVersion: execCredentialAPIVersion,
+	}
+}
+
+// buildGKEExecProviderConfig converts a gke-gcloud-auth-plugin exec plugin
+// into "argocd-k8s-auth gcp", which authenticates with the Application
+// Default Credentials of ArgoCD, e.g. through GKE workload identity.
+func buildGKEExecProviderConfig(exec *clientcmdapi.ExecConfig) *argocdv1alpha1.ExecProviderConfig {
+	return &argocdv1alpha1.ExecProviderConfig{
+		Command:    argocdK8sAuthCommand,
+		Args:       []string{"gcp"},
+		Env:        mapEnv(exec.Env),
+		APIVersion: execCredentialAPIVersion,
+	}
+}
+
+// buildAWSAuthConfig extracts the EKS cluster name and role ARN from an
+// "aws eks get-token" or "aws-iam-authenticator token" exec plugin, so that
+// ArgoCD can authenticate with its own AWS credentials. It returns nil for
+// any other exec plugin.
+func buildAWSAuthConfig(exec *clientcmdapi.ExecConfig) *argocdv1alpha1.AWSAuthConfig {
+	flags := parseFlags(exec.Args)
+	switch filepath.Base(exec.Command) {
+	case awsCommand:
+		if len(exec.Args) < 2 || exec.Args[0] != "eks" || exec.Args[1] != "get-token" {
+			return nil
+		}
+		return &argocdv1alpha1.AWSAuthConfig{
+			ClusterName: flags["cluster-name"],
+			RoleARN:     flags["role-arn"],
+		}
+	case awsIAMAuthenticatorCommand:
+		if len(exec.Args) < 1 || exec.Args[0] != "token" {
+			return nil
+		}
+		awsAuthConfig := &argocdv1alpha1.AWSAuthConfig{
+			ClusterName: flags["cluster-id"],
+			RoleARN:     flags["role"],
+		}
+		if v, ok := flags["i"]; ok {
+			awsAuthConfig.ClusterName = v
+		}
+		if v, ok := flags["r"]; ok {
+			awsAuthConfig.RoleARN = v
+		}
+		return awsAuthConfig
+	default:
+		return nil
+	}
+}
+
+// hasStaticCredentials determines whether the rest config can authenticate
+// without its exec credential plugin.
+func hasStaticCredentials(config *rest.Config) bool {
+	return config.BearerToken != "" ||
+		(len(config.TLSClientConfig.CertData) > 0 && len(config.TLSClientConfig.KeyData) > 0)
+}
+
+// parseFlags returns the value of every flag in args, accepting both the
+// "--flag value" and "--flag=value" forms. Boolean flags are given an empty
+// value when they are followed by another flag.
+func parseFlags(args []string) map[string]string {
+	flags := map[string]string{}
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			continue
+		}
+		name := strings.TrimLeft(args[i], "-")
+		if n, v, ok := strings.Cut(name, "="); ok {
+			flags[n] = v
+			continue
+		}
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			flags[name] = args[i+1]
+			i++
+			continue
+		}
+		flags[name] = ""
+	}
+	return flags
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/rest"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+var _ = Describe("Exec credential plugin conversion", func() {
+	When("a kubeconfig uses kubelogin", func() {
+		exec := &clientcmdapi.ExecConfig{
+			Command: "kubelogin",
+			Args: []string{
+				"get-token",
+				"--login", "spn",
+				"--server-id", "6dae42f8-4368-4678-94ff-3960e28e3630",
+				"--client-id=client",
+				"--tenant-id", "tenant",
+				"--environment", "AzurePublicCloud",
+			},
+			Env: []clientcmdapi.ExecEnvVar{
+				{Name: "AZURE_CLIENT_SECRET", Value: "secret"},
+			},
+			APIVersion: "client.authentication.k8s.io/v1beta1",
+		}
+
+		It("should convert it into argocd-k8s-auth", func() {
+			epc := buildExecProviderConfig(exec)
+			Expect(epc.Command).To(Equal("argocd-k8s-auth"))
+			Expect(epc.Args).To(Equal([]string{"azure"}))
+			Expect(epc.Env).To(Equal(map[string]string{
+				"AAD_SERVER_APPLICATION_ID": "6dae42f8-4368-4678-94ff-3960e28e3630",
+				"AAD_ENVIRONMENT_NAME":      "AzurePublicCloud",
+			}))
+		})
+
+		It("should drop the identity and credentials of the kubeconfig", func() {
+			spn := exec.DeepCopy()
+			spn.Args = append(spn.Args, "--client-secret", "secret", "--password", "password")
+			epc := buildExecProviderConfig(spn)
+			Expect(epc.Env).NotTo(HaveKey("AZURE_CLIENT_ID"))
+			Expect(epc.Env).NotTo(HaveKey("AZURE_TENANT_ID"))
+			Expect(epc.Env).NotTo(HaveKey("AZURE_CLIENT_SECRET"))
+			Expect(epc.Env).NotTo(HaveKey("AAD_USER_PRINCIPAL_PASSWORD"))
+		})
+
+		It("should prefer static credentials", func() {
+			cc := buildClusterConfigFromRestConfig(&rest.Config{
+				Host:         "https://aks.example.com:443",
+				ExecProvider: exec,
+				TLSClientConfig: rest.TLSClientConfig{
+					CertData: []byte("cert"),
+					KeyData:  []byte("key"),
+				},
+			})
+			Expect(cc.ExecProviderConfig).To(BeNil())
+			Expect(cc.TLSClientConfig.CertData).To(Equal([]byte("cert")))
+		})
+	})
+
+	When("a kubeconfig uses the gke-gcloud-auth-plugin", func() {
+		It("should convert it into argocd-k8s-auth", func() {
+			epc := buildExecProviderConfig(&clientcmdapi.ExecConfig{
+				Command:            "gke-gcloud-auth-plugin",
+				APIVersion:         "client.authentication.k8s.io/v1beta1",
+				ProvideClusterInfo: true,
+			})
+			Expect(epc.Command).To(Equal("argocd-k8s-auth"))
+			Expect(epc.Args).To(Equal([]string{"gcp"}))
+			Expect(epc.APIVersion).To(Equal("client.authentication.k8s.io/v1beta1"))
+		})
+	})
+
+	When("a kubeconfig uses the aws CLI", func() {
+		It("should convert it into an AWS auth config", func() {
+			cc := buildClusterConfigFromRestConfig(&rest.Config{
+				Host: "https://eks.example.com:443",
+				ExecProvider: &clientcmdapi.ExecConfig{
+					Command: "aws",
+					Args: []string{
+						"eks", "get-token",
+						"--cluster-name", "eks-cluster",
+						"--region", "us-east-1",
+						"--role-arn=arn:aws:iam::123456789012:role/argocd",
+					},
+					APIVersion: "client.authentication.k8s.io/v1beta1",
+				},
+			})
+			Expect(cc.ExecProviderConfig).To(BeNil())
+			Expect(cc.AWSAuthConfig).NotTo(BeNil())
+			Expect(cc.AWSAuthConfig.ClusterName).To(Equal("eks-cluster"))
+			Expect(cc.AWSAuthConfig.RoleARN).To(Equal("arn:aws:iam::123456789012:role/argocd"))
+		})
+	})
+
+	When("a kubeconfig uses aws-iam-authenticator", func() {
+		It("should convert it into an AWS auth config", func() {
+			awsAuthConfig := buildAWSAuthConfig(&clientcmdapi.ExecConfig{
+				Command:    "aws-iam-authenticator",
+				Args:       []string{"token", "-i", "eks-cluster"},
+				APIVersion: "client.authentication.k8s.io/v1beta1",
+			})
+			Expect(awsAuthConfig).NotTo(BeNil())
+			Expect(awsAuthConfig.ClusterName).To(Equal("eks-cluster"))
+			Expect(awsAuthConfig.RoleARN).To(BeEmpty())
+		})
+	})
+
+	When("a kubeconfig uses an unknown exec plugin", func() {
+		It("should copy it verbatim", func() {
+			epc := buildExecProviderConfig(&clientcmdapi.ExecConfig{
+				Command:    "my-plugin",
+				Args:       []string{"token"},
+				APIVersion: "client.authentication.k8s.io/v1",
+			})
+			Expect(epc.Command).To(Equal("my-plugin"))
+			Expect(epc.Args).To(Equal([]string{"token"}))
+			Expect(epc.APIVersion).To(Equal("client.authentication.k8s.io/v1"))
+		})
+	})
+})
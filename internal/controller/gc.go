@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/superorbital/capargo/pkg/common"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	apimachinerytypes "k8s.io/apimachinery/pkg/types"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var gcLogger = logf.Log.WithName("capargo-gc")
+
+// GarbageCollector removes the ArgoCD cluster secrets whose cluster does not
+// exist anymore, on startup and then periodically. It catches the clusters
+// deleted while capargo was not running.
+type GarbageCollector struct {
+	*ClusterKubeconfigReconciler
+
+	// Interval between two sweeps. When it is not positive, only the startup
+	// sweep is run, and repeated until the orphaned secrets it found have
+	// been orphaned for the grace period.
+	Interval time.Duration
+
+	// GracePeriod is how long a secret must have been orphaned before it is
+	// removed. When a secret was first found orphaned is recorded on it, so
+	// that the grace period outlives restarts of capargo.
+	GracePeriod time.Duration
+
+	// DryRun only logs the secrets that would be removed.
+	DryRun bool
+}
+
+// Start runs the garbage collector until the context is done.
+func (g *GarbageCollector) Start(ctx context.Context) error {
+	next := g.collect(ctx)
+	if g.Interval <= 0 {
+		for !next.IsZero() {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Until(next)):
+				next = g.collect(ctx)
+			}
+		}
+		return nil
+	}
+	ticker := time.NewTicker(g.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			g.collect(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection makes sure that only the leader removes secrets.
+func (g *GarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// collect runs a single sweep, and returns when the next orphaned secret
+// reaches the grace period, or the zero time if none is waiting for it.
+// Errors are logged, so that the next sweep can try again.
+func (g *GarbageCollector) collect(ctx context.Context) time.Time {
+	list := corev1.SecretList{}
+	if err := g.List(ctx, &list,
+		client.InNamespace(g.ArgoNamespace),
+		client.HasLabels{common.ControllerNameLabel},
+	); err != nil {
+		gcLogger.Error(err, "could not list ArgoCD cluster secrets")
+		return time.Time{}
+	}
+
+	now := time.Now()
+	next := time.Time{}
+	for i := range list.Items {
+		secret := &list.Items[i]
+		orphaned, err := g.isOrphaned(ctx, secret)
+		if err != nil {
+			gcLogger.Error(err, "could not get the cluster of the ArgoCD cluster secret",
+				"secret namespace", secret.GetNamespace(), "secret name", secret.GetName(),
+			)
+			continue
+		}
+		if !orphaned {
+			// The cluster came back, or was paused, before the grace
+			// period ended.
+			if err := g.setOrphanedSince(ctx, secret, time.Time{}); err != nil {
+				gcLogger.Error(err, "could not update ArgoCD cluster secret",
+					"secret namespace", secret.GetNamespace(), "secret name", secret.GetName(),
+				)
+			}
+			continue
+		}
+
+		since, ok := orphanedSince(secret)
+		if !ok {
+			since = now
+			if err := g.setOrphanedSince(ctx, secret, since); err != nil {
+				gcLogger.Error(err, "could not update ArgoCD cluster secret",
+					"secret namespace", secret.GetNamespace(), "secret name", secret.GetName(),
+				)
+				continue
+			}
+		}
+		if expiry := since.Add(g.GracePeriod); now.Before(expiry) {
+			gcLogger.V(4).Info("ArgoCD cluster secret is orphaned, waiting for the grace period",
+				"secret namespace", secret.GetNamespace(), "secret name", secret.GetName(),
+				"orphaned since", since,
+			)
+			if next.IsZero() || expiry.Before(next) {
+				next = expiry
+			}
+			continue
+		}
+		if g.DryRun {
+			gcLogger.Info("Would delete orphaned ArgoCD cluster secret",
+				"secret namespace", secret.GetNamespace(), "secret name", secret.GetName(),
+			)
+			continue
+		}
+		if err := g.Delete(ctx, secret, &client.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			gcLogger.Error(err, "could not delete orphaned ArgoCD cluster secret",
+				"secret namespace", secret.GetNamespace(), "secret name", secret.GetName(),
+			)
+			continue
+		}
+		gcLogger.Info("Deleted orphaned ArgoCD cluster secret",
+			"secret namespace", secret.GetNamespace(), "secret name", secret.GetName(),
+		)
+		registrations.WithLabelValues(deletedOperation).Inc()
+		forgetCluster(secret.Annotations[common.ClusterNamespaceAnnotation], secret.Annotations[common.ClusterNameAnnotation])
+	}
+	return next
+}
+
+// orphanedSince returns when the ArgoCD cluster secret was first found
+// orphaned, if it was.
+func orphanedSince(secret *corev1.Secret) (time.Time, bool) {
+	since, err := time.Parse(time.RFC3339, secret.Annotations[common.OrphanedSinceAnnotation])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return since, true
+}
+
+// setOrphanedSince records on the ArgoCD cluster secret of this instance when
+// it was first found orphaned, or removes the record for the zero time.
+func (g *GarbageCollector) setOrphanedSince(ctx context.Context, secret *corev1.Secret, since time.Time) error {
+	if secret.Labels[common.ClusterIDLabel] != g.ClusterID {
+		return nil
+	}
+	_, ok := secret.Annotations[common.OrphanedSinceAnnotation]
+	if since.IsZero() && !ok {
+		return nil
+	}
+	patch := client.MergeFrom(secret.DeepCopy())
+	if since.IsZero() {
+		delete(secret.Annotations, common.OrphanedSinceAnnotation)
+	} else {
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[common.OrphanedSinceAnnotation] = since.UTC().Format(time.RFC3339)
+	}
+	return g.Patch(ctx, secret, patch)
+}
+
+// isOrphaned determines whether the ArgoCD cluster secret belongs to this
+// instance of capargo and its cluster does not exist anymore. Only the
+// secrets labelled with the ID of this instance are considered, since the
+// unlabelled secrets of older versions may have been registered by another
+// management cluster. The secrets of clusters outside of the watched
+// namespaces, or last seen paused, are never orphaned.
+func (g *GarbageCollector) isOrphaned(ctx context.Context, secret *corev1.Secret) (bool, error) {
+	if secret.Labels[common.ClusterIDLabel] != g.ClusterID {
+		return false, nil
+	}
+	name, ok := secret.Annotations[common.ClusterNameAnnotation]
+	if !ok {
+		return false, nil
+	}
+	namespace, ok := secret.Annotations[common.ClusterNamespaceAnnotation]
+	if !ok {
+		return false, nil
+	}
+	if !g.isWatchedNamespace(namespace) {
+		return false, nil
+	}
+	if isPausedSecret(secret) {
+		return false, nil
+	}
+	key := apimachinerytypes.NamespacedName{Name: name, Namespace: namespace}
+	err := g.Get(ctx, key, &capiv1beta1.Cluster{}, &client.GetOptions{})
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	return false, err
+}
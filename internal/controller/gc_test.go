@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	argocdcommon "github.com/argoproj/argo-cd/v2/common"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/uuid"
+	"github.com/superorbital/capargo/pkg/common"
+	"github.com/superorbital/capargo/pkg/types"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("Garbage collection of ArgoCD cluster secrets", func() {
+	var (
+		argoNamespace string
+		orphan        corev1.Secret
+		other         corev1.Secret
+		legacy        corev1.Secret
+		registered    corev1.Secret
+		cluster       capiv1beta1.Cluster
+	)
+
+	argoSecret := func(name, clusterName, id string) corev1.Secret {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: argoNamespace,
+				Annotations: map[string]string{
+					common.ClusterNameAnnotation:      clusterName,
+					common.ClusterNamespaceAnnotation: argoNamespace,
+				},
+				Labels: map[string]string{
+					argocdcommon.LabelKeySecretType: argocdcommon.LabelValueSecretTypeCluster,
+					common.ControllerNameLabel:      common.ControllerName,
+					common.ClusterIDLabel:           id,
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		By("creating ArgoCD cluster secrets with and without their cluster")
+		argoNamespace = fmt.Sprintf("ns-%s", uuid.New().String())
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: argoNamespace}})).To(Succeed())
+		cluster = capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "registered",
+				Namespace: argoNamespace,
+			},
+		}
+		Expect(k8sClient.Create(ctx, &cluster, &client.CreateOptions{})).To(Succeed())
+		orphan = argoSecret("orphan", "deleted", "envTest")
+		other = argoSecret("other", "deleted", "other")
+		legacy = argoSecret("legacy", "deleted", "")
+		delete(legacy.Labels, common.ClusterIDLabel)
+		registered = argoSecret("registered", cluster.Name, "envTest")
+		for _, s := range []*corev1.Secret{&orphan, &other, &legacy, &registered} {
+			Expect(k8sClient.Create(ctx, s, &client.CreateOptions{})).To(Succeed())
+		}
+	})
+
+	newGarbageCollector := func() *GarbageCollector {
+		return &GarbageCollector{
+			ClusterKubeconfigReconciler: &ClusterKubeconfigReconciler{
+				Client: k8sClient,
+				Options: types.Options{
+					ClusterID:     "envTest",
+					ArgoNamespace: argoNamespace,
+				},
+			},
+		}
+	}
+
+	exists := func(s *corev1.Secret) bool {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(s), &corev1.Secret{}, &client.GetOptions{})
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		return err == nil
+	}
+
+	It("should only remove the orphaned secrets of this instance", func() {
+		newGarbageCollector().collect(ctx)
+		Expect(exists(&orphan)).To(BeFalse())
+		Expect(exists(&other)).To(BeTrue())
+		Expect(exists(&legacy)).To(BeTrue())
+		Expect(exists(&registered)).To(BeTrue())
+	})
+
+	It("should not remove anything in dry-run mode", func() {
+		gc := newGarbageCollector()
+		gc.DryRun = true
+		gc.collect(ctx)
+		Expect(exists(&orphan)).To(BeTrue())
+	})
+
+	It("should wait for the grace period, across restarts", func() {
+		gc := newGarbageCollector()
+		gc.GracePeriod = time.Hour
+		next := gc.collect(ctx)
+		Expect(next).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+		Expect(exists(&orphan)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&orphan), &orphan)).To(Succeed())
+		Expect(orphan.Annotations).To(HaveKey(common.OrphanedSinceAnnotation))
+
+		By("expiring the grace period before restarting")
+		patch := client.MergeFrom(orphan.DeepCopy())
+		orphan.Annotations[common.OrphanedSinceAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+		Expect(k8sClient.Patch(ctx, &orphan, patch)).To(Succeed())
+		gc = newGarbageCollector()
+		gc.GracePeriod = time.Hour
+		Expect(gc.collect(ctx).IsZero()).To(BeTrue())
+		Expect(exists(&orphan)).To(BeFalse())
+	})
+
+	It("should forget when a secret was orphaned once its cluster is back", func() {
+		patch := client.MergeFrom(registered.DeepCopy())
+		registered.Annotations[common.OrphanedSinceAnnotation] = time.Now().UTC().Format(time.RFC3339)
+		Expect(k8sClient.Patch(ctx, &registered, patch)).To(Succeed())
+		newGarbageCollector().collect(ctx)
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&registered), &registered)).To(Succeed())
+		Expect(registered.Annotations).NotTo(HaveKey(common.OrphanedSinceAnnotation))
+	})
+})
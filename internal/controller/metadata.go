@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+)
+
+// propagate returns the entries of src whose key is one of keys or starts
+// with one of prefixes.
+func propagate(src map[string]string, keys, prefixes []string) map[string]string {
+	out := map[string]string{}
+	for k, v := range src {
+		if slices.Contains(keys, k) || slices.ContainsFunc(prefixes, func(p string) bool {
+			return strings.HasPrefix(k, p)
+		}) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// mergeInto copies the entries of src into dst, without overwriting the
+// entries already present in dst.
+func mergeInto(dst, src map[string]string) {
+	for k, v := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cluster metadata propagation", func() {
+	src := map[string]string{
+		"env.example.com/stage":  "production",
+		"env.example.com/region": "eu-west-1",
+		"team":                   "platform",
+		"owner":                  "alice",
+	}
+
+	It("should select the keys by name and by prefix", func() {
+		Expect(propagate(src, []string{"team"}, []string{"env.example.com/"})).To(Equal(map[string]string{
+			"env.example.com/stage":  "production",
+			"env.example.com/region": "eu-west-1",
+			"team":                   "platform",
+		}))
+	})
+
+	It("should select nothing by default", func() {
+		Expect(propagate(src, nil, nil)).To(BeEmpty())
+	})
+
+	It("should not overwrite existing entries", func() {
+		dst := map[string]string{"team": "capargo"}
+		mergeInto(dst, map[string]string{"team": "platform", "owner": "alice"})
+		Expect(dst).To(Equal(map[string]string{"team": "capargo", "owner": "alice"}))
+	})
+})
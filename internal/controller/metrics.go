@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	corev1 "k8s.io/api/core/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Operations counted by the registrations metric.
+const (
+	createdOperation = "created"
+	updatedOperation = "updated"
+	deletedOperation = "deleted"
+)
+
+var (
+	// clusterRegistered reports whether each cluster is registered in
+	// ArgoCD. Clusters waiting for their control plane or failing to
+	// register are reported as not registered, with the reason of their
+	// ArgoCDRegistered condition.
+	clusterRegistered = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capargo_cluster_registered",
+		Help: "Whether the cluster is registered in ArgoCD, by cluster, control plane kind and reason when it is not.",
+	}, []string{"namespace", "name", "kind", "reason"})
+
+	// registrations counts the ArgoCD cluster secrets written and deleted.
+	registrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capargo_registrations_total",
+		Help: "Number of ArgoCD cluster secrets created, updated and deleted.",
+	}, []string{"operation"})
+
+	// registrationFailures counts the failed registrations by reason.
+	registrationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capargo_registration_failures_total",
+		Help: "Number of failed registrations of clusters in ArgoCD, by reason.",
+	}, []string{"reason"})
+
+	// registrationDuration measures the time between the control plane of a
+	// cluster becoming ready and the creation of its ArgoCD cluster secret.
+	// Secrets that already existed, e.g. adopted or moved in, are not
+	// observed, as their registration predates capargo.
+	registrationDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "capargo_registration_duration_seconds",
+		Help:    "Time from the control plane of a cluster becoming ready to the creation of its ArgoCD cluster secret.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	})
+
+	// kubeconfigExpiry reports when the client certificate registered for
+	// each cluster expires.
+	kubeconfigExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capargo_kubeconfig_expiry_timestamp_seconds",
+		Help: "Expiry time of the client certificate registered in ArgoCD for the cluster, in seconds since the epoch.",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		clusterRegistered,
+		registrations,
+		registrationFailures,
+		registrationDuration,
+		kubeconfigExpiry,
+	)
+}
+
+// setRegistered reports whether the cluster is registered in ArgoCD, and the
+// reason why it is not.
+func setRegistered(cluster *capiv1beta1.Cluster, registered bool, reason string) {
+	// Drop the series of a previous control plane kind.
+	clusterRegistered.DeletePartialMatch(prometheus.Labels{"namespace": cluster.Namespace, "name": cluster.Name})
+	kind := ""
+	if cluster.Spec.ControlPlaneRef != nil {
+		kind = cluster.Spec.ControlPlaneRef.Kind
+	}
+	value := 0.0
+	if registered {
+		value = 1
+		reason = ""
+	}
+	clusterRegistered.WithLabelValues(cluster.Namespace, cluster.Name, kind, reason).Set(value)
+}
+
+// forgetCluster removes the series of a cluster that is not meant to be
+// registered anymore.
+func forgetCluster(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	clusterRegistered.DeletePartialMatch(labels)
+	kubeconfigExpiry.DeletePartialMatch(labels)
+}
+
+// observeRegistration records the time elapsed since the control plane of a
+// cluster became ready, once its ArgoCD cluster secret has been created.
+func observeRegistration(cluster *capiv1beta1.Cluster) {
+	ready := conditions.Get(cluster, capiv1beta1.ControlPlaneReadyCondition)
+	if ready == nil || ready.Status != corev1.ConditionTrue || ready.LastTransitionTime.IsZero() {
+		return
+	}
+	registrationDuration.Observe(time.Since(ready.LastTransitionTime.Time).Seconds())
+}
+
+// setKubeconfigExpiry reports the expiry of the client certificate of the
+// kubeconfig, if it has one.
+func setKubeconfigExpiry(cluster *capiv1beta1.Cluster, config *rest.Config) {
+	expiry, ok := certificateExpiry(config.TLSClientConfig.CertData)
+	if !ok {
+		kubeconfigExpiry.DeleteLabelValues(cluster.Namespace, cluster.Name)
+		return
+	}
+	kubeconfigExpiry.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(expiry.Unix()))
+}
+
+// certificateExpiry returns the expiry of the first PEM-encoded certificate.
+func certificateExpiry(data []byte) (time.Time, bool) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return time.Time{}, false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return cert.NotAfter, true
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("Registration metrics", func() {
+	It("should report a single series per cluster", func() {
+		// Other specs leave the series of their clusters behind, so only
+		// the series added by this spec are counted.
+		before := testutil.CollectAndCount(clusterRegistered)
+		name := "metrics-" + uuid.New().String()
+		cluster := &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "metrics"},
+			Spec: capiv1beta1.ClusterSpec{
+				ControlPlaneRef: &corev1.ObjectReference{Kind: "VCluster"},
+			},
+		}
+		setRegistered(cluster, false, WaitingForControlPlaneReason)
+		Expect(testutil.ToFloat64(clusterRegistered.WithLabelValues("metrics", name, "VCluster", WaitingForControlPlaneReason))).To(Equal(0.0))
+		Expect(testutil.CollectAndCount(clusterRegistered)).To(Equal(before + 1))
+
+		By("failing to register the cluster")
+		setRegistered(cluster, false, KubeconfigNotFoundReason)
+		Expect(testutil.ToFloat64(clusterRegistered.WithLabelValues("metrics", name, "VCluster", KubeconfigNotFoundReason))).To(Equal(0.0))
+		Expect(testutil.CollectAndCount(clusterRegistered)).To(Equal(before + 1))
+
+		By("changing the control plane kind")
+		cluster.Spec.ControlPlaneRef.Kind = "KamajiControlPlane"
+		setRegistered(cluster, true, "")
+		Expect(testutil.ToFloat64(clusterRegistered.WithLabelValues("metrics", name, "KamajiControlPlane", ""))).To(Equal(1.0))
+		Expect(testutil.CollectAndCount(clusterRegistered)).To(Equal(before + 1))
+
+		By("forgetting the cluster")
+		forgetCluster("metrics", name)
+		Expect(testutil.CollectAndCount(clusterRegistered)).To(Equal(before))
+	})
+
+	It("should read the expiry of the client certificate", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		notAfter := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "capargo"},
+			NotBefore:    time.Now(),
+			NotAfter:     notAfter,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).NotTo(HaveOccurred())
+
+		expiry, ok := certificateExpiry(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+		Expect(ok).To(BeTrue())
+		Expect(expiry).To(BeTemporally("==", notAfter))
+
+		_, ok = certificateExpiry(nil)
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/superorbital/capargo/pkg/common"
+	"github.com/superorbital/capargo/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("ArgoCD cluster project", func() {
+	reconciler := &ClusterKubeconfigReconciler{
+		Options: types.Options{
+			NamespaceProjects: map[string]string{
+				"tenant-a": "project-a",
+			},
+		},
+	}
+
+	It("should use the project of the namespace", func() {
+		cluster := &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "tenant-a"},
+		}
+		Expect(reconciler.argoProject(cluster)).To(Equal("project-a"))
+	})
+
+	It("should prefer the project annotation", func() {
+		cluster := &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "prod",
+				Namespace: "tenant-a",
+				Annotations: map[string]string{
+					common.ProjectAnnotation: "project-b",
+				},
+			},
+		}
+		Expect(reconciler.argoProject(cluster)).To(Equal("project-b"))
+	})
+
+	It("should leave unmapped clusters global", func() {
+		cluster := &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "tenant-c"},
+		}
+		Expect(reconciler.argoProject(cluster)).To(BeEmpty())
+	})
+})
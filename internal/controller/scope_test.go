@@ -0,0 +1,54 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/superorbital/capargo/pkg/common"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("ArgoCD cluster scope", func() {
+	It("should leave clusters cluster-wide by default", func() {
+		cluster := &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "tenant-a"},
+		}
+		data := map[string][]byte{}
+		Expect(setArgoScope(cluster, data)).To(Succeed())
+		Expect(data).To(BeEmpty())
+	})
+
+	It("should restrict clusters to the annotated namespaces", func() {
+		cluster := &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "prod",
+				Namespace: "tenant-a",
+				Annotations: map[string]string{
+					common.ArgoNamespacesAnnotation:       "team-a, team-b,",
+					common.ArgoClusterResourcesAnnotation: "True",
+				},
+			},
+		}
+		data := map[string][]byte{}
+		Expect(setArgoScope(cluster, data)).To(Succeed())
+		Expect(data).To(Equal(map[string][]byte{
+			"namespaces":       []byte("team-a,team-b"),
+			"clusterResources": []byte("true"),
+		}))
+	})
+
+	It("should reject invalid cluster resources annotations", func() {
+		cluster := &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "prod",
+				Namespace: "tenant-a",
+				Annotations: map[string]string{
+					common.ArgoClusterResourcesAnnotation: "sometimes",
+				},
+			},
+		}
+		Expect(setArgoScope(cluster, map[string][]byte{})).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/superorbital/capargo/pkg/common"
+	"github.com/superorbital/capargo/pkg/types"
+	"k8s.io/apimachinery/pkg/labels"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("Cluster selection", func() {
+	cluster := func(l, a map[string]string) *capiv1beta1.Cluster {
+		return &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "ci",
+				Namespace:   "tenant-a",
+				Labels:      l,
+				Annotations: a,
+			},
+		}
+	}
+
+	It("should select every cluster by default", func() {
+		reconciler := &ClusterKubeconfigReconciler{}
+		Expect(reconciler.isSelected(cluster(nil, nil))).To(BeTrue())
+	})
+
+	It("should not select skipped clusters", func() {
+		reconciler := &ClusterKubeconfigReconciler{}
+		Expect(reconciler.isSelected(cluster(nil, map[string]string{common.SkipAnnotation: "true"}))).To(BeFalse())
+		Expect(reconciler.isSelected(cluster(nil, map[string]string{common.SkipAnnotation: "false"}))).To(BeTrue())
+	})
+
+	It("should only select the clusters matching the selector", func() {
+		selector, err := labels.Parse("argocd.example.com/register=true,env!=ci")
+		Expect(err).NotTo(HaveOccurred())
+		reconciler := &ClusterKubeconfigReconciler{
+			Options: types.Options{ClusterSelector: selector},
+		}
+		Expect(reconciler.isSelected(cluster(map[string]string{"argocd.example.com/register": "true"}, nil))).To(BeTrue())
+		Expect(reconciler.isSelected(cluster(map[string]string{"argocd.example.com/register": "true", "env": "ci"}, nil))).To(BeFalse())
+		Expect(reconciler.isSelected(cluster(nil, nil))).To(BeFalse())
+	})
+})
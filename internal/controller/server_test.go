@@ -0,0 +1,58 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/superorbital/capargo/pkg/common"
+	"k8s.io/client-go/rest"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("ArgoCD cluster server", func() {
+	reconciler := &ClusterKubeconfigReconciler{}
+
+	It("should keep the kubeconfig server by default", func() {
+		cluster := &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "tenant-a"},
+		}
+		config := &rest.Config{Host: "https://localhost:8443"}
+		Expect(reconciler.setServer(ctx, cluster, config)).To(Succeed())
+		Expect(config.Host).To(Equal("https://localhost:8443"))
+		Expect(config.TLSClientConfig.ServerName).To(BeEmpty())
+	})
+
+	It("should override the server and keep verifying the original host", func() {
+		cluster := &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dev",
+				Namespace: "tenant-a",
+				Annotations: map[string]string{
+					common.ServerAnnotation: "https://dev.tenant-a.svc:443",
+				},
+			},
+		}
+		config := &rest.Config{Host: "https://localhost:8443"}
+		Expect(reconciler.setServer(ctx, cluster, config)).To(Succeed())
+		Expect(config.Host).To(Equal("https://dev.tenant-a.svc:443"))
+		Expect(config.TLSClientConfig.ServerName).To(Equal("localhost"))
+	})
+
+	It("should prefer the TLS server name annotation", func() {
+		cluster := &capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dev",
+				Namespace: "tenant-a",
+				Annotations: map[string]string{
+					common.ServerAnnotation:        "https://dev.tenant-a.svc:443",
+					common.TLSServerNameAnnotation: "dev.example.com",
+				},
+			},
+		}
+		config := &rest.Config{Host: "localhost:8443"}
+		Expect(reconciler.setServer(ctx, cluster, config)).To(Succeed())
+		Expect(config.TLSClientConfig.ServerName).To(Equal("dev.example.com"))
+	})
+})
@@ -17,6 +17,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
+	capargov1alpha1 "github.com/superorbital/capargo/api/v1alpha1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -95,6 +96,18 @@ var _ = BeforeSuite(func() {
 			Scope:        apiextensionsv1.NamespaceScoped,
 			GroupVersion: capiv1beta1.GroupVersion,
 		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "application",
+				Plural:   "applications",
+				Kind:     "Application",
+			},
+			Scope: apiextensionsv1.NamespaceScoped,
+			GroupVersion: schema.GroupVersion{
+				Group:   "argoproj.io",
+				Version: "v1alpha1",
+			},
+		},
 	}
 	testCRDs := createCRDs(crds)
 	By("bootstrapping the envtest test environment")
@@ -111,6 +124,8 @@ var _ = BeforeSuite(func() {
 	// Add CRDs to Scheme
 	err = capiv1beta1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
+	err = capargov1alpha1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
 
 	// Create client for envTest
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// DefaultArgoClusterNameTemplate is the default template of the name
+	// under which clusters are displayed in ArgoCD.
+	DefaultArgoClusterNameTemplate = "{{ .Cluster.Name }}"
+
+	// DefaultSecretNameTemplate is the default template of the name of the
+	// ArgoCD cluster secrets.
+	DefaultSecretNameTemplate = "{{ .Cluster.Namespace }}-{{ .Cluster.Name }}"
+)
+
+// templateData is the data available to the name templates.
+type templateData struct {
+	// ID is the value of the --id flag, naming the management cluster.
+	ID      string
+	Cluster templateCluster
+}
+
+type templateCluster struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// ParseNameTemplate parses a name template, so that invalid templates can be
+// rejected on startup.
+func ParseNameTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// renderNameTemplate renders a name template for a cluster, falling back to
+// the default template when text is empty.
+func renderNameTemplate(name, text, defaultText, id string, cluster *capiv1beta1.Cluster) (string, error) {
+	if text == "" {
+		text = defaultText
+	}
+	tmpl, err := ParseNameTemplate(name, text)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s: %v", name, err)
+	}
+	data := templateData{
+		ID: id,
+		Cluster: templateCluster{
+			Name:        cluster.Name,
+			Namespace:   cluster.Namespace,
+			Labels:      cluster.Labels,
+			Annotations: cluster.Annotations,
+		},
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("could not render %s for cluster %s/%s: %v",
+			name, cluster.Namespace, cluster.Name, err,
+		)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// argoClusterName returns the name under which the cluster is displayed in
+// ArgoCD.
+func (c *ClusterKubeconfigReconciler) argoClusterName(cluster *capiv1beta1.Cluster) (string, error) {
+	name, err := renderNameTemplate("argo cluster name template",
+		c.ArgoClusterNameTemplate, DefaultArgoClusterNameTemplate, c.ClusterID, cluster,
+	)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "", fmt.Errorf("argo cluster name template rendered an empty name for cluster %s/%s",
+			cluster.Namespace, cluster.Name,
+		)
+	}
+	return name, nil
+}
+
+// argoSecretName returns the name of the ArgoCD cluster secret of the
+// cluster.
+func (c *ClusterKubeconfigReconciler) argoSecretName(cluster *capiv1beta1.Cluster) (string, error) {
+	name, err := renderNameTemplate("secret name template",
+		c.SecretNameTemplate, DefaultSecretNameTemplate, c.ClusterID, cluster,
+	)
+	if err != nil {
+		return "", err
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("secret name template rendered an invalid name %q for cluster %s/%s: %s",
+			name, cluster.Namespace, cluster.Name, strings.Join(errs, ", "),
+		)
+	}
+	return name, nil
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/superorbital/capargo/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("ArgoCD cluster name templates", func() {
+	cluster := &capiv1beta1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prod",
+			Namespace: "tenant-a",
+			Labels: map[string]string{
+				"env.example.com/region": "eu-west-1",
+			},
+		},
+	}
+
+	It("should use the default templates when none are set", func() {
+		reconciler := &ClusterKubeconfigReconciler{}
+		name, err := reconciler.argoClusterName(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("prod"))
+		secretName, err := reconciler.argoSecretName(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secretName).To(Equal("tenant-a-prod"))
+	})
+
+	It("should render the templates with the cluster and the ID", func() {
+		reconciler := &ClusterKubeconfigReconciler{
+			Options: types.Options{
+				ClusterID:               "mgmt",
+				ArgoClusterNameTemplate: `{{ .Cluster.Namespace }}/{{ .Cluster.Name }} ({{ index .Cluster.Labels "env.example.com/region" }})`,
+				SecretNameTemplate:      "{{ .ID }}-{{ .Cluster.Namespace }}-{{ .Cluster.Name }}",
+			},
+		}
+		name, err := reconciler.argoClusterName(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("tenant-a/prod (eu-west-1)"))
+		secretName, err := reconciler.argoSecretName(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secretName).To(Equal("mgmt-tenant-a-prod"))
+	})
+
+	It("should reject invalid secret names", func() {
+		reconciler := &ClusterKubeconfigReconciler{
+			Options: types.Options{
+				SecretNameTemplate: "{{ .Cluster.Namespace }}/{{ .Cluster.Name }}",
+			},
+		}
+		_, err := reconciler.argoSecretName(cluster)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -1,9 +1,21 @@
 package common
 
 const (
-	slug                       = "superorbital.io"
-	ControllerName             = "capargo"
-	ControllerNameLabel        = ControllerName + "." + slug + "/controller-name"
-	ClusterNameAnnotation      = ControllerName + "." + slug + "/cluster-name"
-	ClusterNamespaceAnnotation = ControllerName + "." + slug + "/cluster-namespace"
+	slug                           = "superorbital.io"
+	ControllerName                 = "capargo"
+	ControllerNameLabel            = ControllerName + "." + slug + "/controller-name"
+	ClusterIDLabel                 = ControllerName + "." + slug + "/cluster-id"
+	ClusterFinalizer               = ControllerName + "." + slug + "/argocd-cluster"
+	ClusterNameAnnotation          = ControllerName + "." + slug + "/cluster-name"
+	ClusterNamespaceAnnotation     = ControllerName + "." + slug + "/cluster-namespace"
+	AWSClusterNameAnnotation       = ControllerName + "." + slug + "/aws-cluster-name"
+	AWSRoleARNAnnotation           = ControllerName + "." + slug + "/aws-role-arn"
+	ProjectAnnotation              = ControllerName + "." + slug + "/project"
+	ArgoNamespacesAnnotation       = ControllerName + "." + slug + "/argo-namespaces"
+	ArgoClusterResourcesAnnotation = ControllerName + "." + slug + "/argo-cluster-resources"
+	ServerAnnotation               = ControllerName + "." + slug + "/server"
+	TLSServerNameAnnotation        = ControllerName + "." + slug + "/tls-server-name"
+	SkipAnnotation                 = ControllerName + "." + slug + "/skip"
+	PausedAnnotation               = ControllerName + "." + slug + "/paused"
+	OrphanedSinceAnnotation        = ControllerName + "." + slug + "/orphaned-since"
 )
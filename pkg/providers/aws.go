@@ -5,12 +5,22 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	v1beta1AWSManagedControlPlane = "controlplane.cluster.x-k8s.io/v1beta1"
+	v1beta2AWSManagedControlPlane = "controlplane.cluster.x-k8s.io/v1beta2"
 )
 
 type awsManagedControlPlane struct {
+	client.Client
 	Name       string
 	Namespace  string
 	APIVersion string
@@ -30,8 +40,8 @@ func (a awsManagedControlPlane) GetNamespacedName() types.NamespacedName {
 func (a awsManagedControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
 	logger := logf.FromContext(ctx).WithName(loggerName)
 	switch a.APIVersion {
-	case "controlplane.cluster.x-k8s.io/v1beta2":
-		if secret.Type != "cluster.x-k8s.io/secret" {
+	case v1beta1AWSManagedControlPlane, v1beta2AWSManagedControlPlane:
+		if secret.Type != capiv1beta1.ClusterSecretType {
 			logger.V(4).Info("Secret is not a cluster secret",
 				"secret namespace", secret.GetNamespace(),
 				"secret name", secret.GetName(),
@@ -47,8 +57,11 @@ func (a awsManagedControlPlane) IsKubeconfig(ctx context.Context, secret *corev1
 			)
 			return false
 		}
+		// Secrets created before the upgrade to v1beta2 keep referencing
+		// their owner with the v1beta1 API version.
 		or := ors[0]
-		if or.Kind != "AWSManagedControlPlane" {
+		gv, err := schema.ParseGroupVersion(or.APIVersion)
+		if err != nil || gv.Group != controlPlaneGroup || or.Kind != string(awsManagedKind) {
 			logger.V(4).Info("Secret is not owned by AWSManagedControlPlane",
 				"secret namespace", secret.GetNamespace(),
 				"secret name", secret.GetName(),
@@ -78,3 +91,21 @@ func (a awsManagedControlPlane) IsKubeconfig(ctx context.Context, secret *corev1
 		return false
 	}
 }
+
+// GetAWSClusterName returns the name of the EKS cluster backing the
+// AWSManagedControlPlane.
+func (a awsManagedControlPlane) GetAWSClusterName(ctx context.Context) (string, error) {
+	cp := unstructured.Unstructured{}
+	cp.SetAPIVersion(a.APIVersion)
+	cp.SetKind(string(awsManagedKind))
+	cp.SetNamespace(a.Namespace)
+	cp.SetName(a.Name)
+	if err := a.Client.Get(ctx, client.ObjectKeyFromObject(&cp), &cp, &client.GetOptions{}); err != nil {
+		return "", fmt.Errorf("could not get AWSManagedControlPlane %s/%s: %w", a.Namespace, a.Name, err)
+	}
+	name, _, err := unstructured.NestedString(cp.Object, "spec", "eksClusterName")
+	if err != nil {
+		return "", fmt.Errorf("could not read EKS cluster name of AWSManagedControlPlane %s/%s: %w", a.Namespace, a.Name, err)
+	}
+	return name, nil
+}
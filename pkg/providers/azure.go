@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const v1beta1AzureManagedControlPlane = "infrastructure.cluster.x-k8s.io/v1beta1"
+
+// azureManagedControlPlane handles AKS clusters managed by CAPZ, through the
+// "<cluster>-kubeconfig" secret Cluster API itself relies on. For AAD-enabled
+// clusters without local accounts, it relies on the kubelogin exec plugin,
+// which is converted when the cluster is registered in ArgoCD.
+type azureManagedControlPlane struct {
+	client.Client
+	ControlPlaneName string
+	ClusterName      string
+	Namespace        string
+	APIVersion       string
+}
+
+// GetNamespacedName returns the namespace and name of a cluster
+// with an Azure managed control plane.
+func (a azureManagedControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-kubeconfig", a.ClusterName),
+		Namespace: a.Namespace,
+	}
+}
+
+// IsKubeconfig determines whether the secret provided is an
+// AzureManagedControlPlane kubeconfig or not.
+func (a azureManagedControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	switch a.APIVersion {
+	case v1beta1AzureManagedControlPlane:
+		if secret.Name != fmt.Sprintf("%s-kubeconfig", a.ClusterName) {
+			logger.V(4).Info("Secret does not match '*-kubeconfig' pattern",
+				"secret namespace", secret.GetNamespace(),
+				"secret name", secret.GetName(),
+			)
+			return false
+		}
+		if !isClusterKubeconfig(ctx, secret, a.ClusterName) {
+			return false
+		}
+		return isOwnedByControlPlane(ctx, a.Client, secret,
+			a.APIVersion, string(azureManagedKind), a.Namespace, a.ControlPlaneName,
+			controllerOwnership,
+		)
+	default:
+		logger.V(2).Info("APIVersion unsupported for AzureManagedControlPlane",
+			"APIVersion", a.APIVersion,
+		)
+		return false
+	}
+}
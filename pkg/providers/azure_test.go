@@ -0,0 +1,158 @@
+package providers
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("Azure provider tests", func() {
+	When("handling an AKS cluster with its control plane", func() {
+		var clusterName = "aks-cluster"
+		var clusterNamespace = "aks-cluster-namespace"
+		var controlPlane = unstructured.Unstructured{}
+		var cluster = capiv1beta1.Cluster{}
+		var kubeconfig = corev1.Secret{}
+
+		BeforeEach(func() {
+			clusterNamespace = fmt.Sprintf("%s-%d", clusterNamespace, time.Now().UnixMilli())
+			controlPlane = unstructured.Unstructured{}
+			controlPlane.SetAPIVersion(v1beta1AzureManagedControlPlane)
+			controlPlane.SetKind("AzureManagedControlPlane")
+			controlPlane.SetName(clusterName + "-control-plane")
+			controlPlane.SetNamespace(clusterNamespace)
+			cluster = capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName,
+					Namespace: clusterNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Name:       controlPlane.GetName(),
+						Namespace:  controlPlane.GetNamespace(),
+						APIVersion: v1beta1AzureManagedControlPlane,
+						Kind:       "AzureManagedControlPlane",
+					},
+				},
+			}
+			kubeconfig = corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName + "-kubeconfig",
+					Namespace: clusterNamespace,
+					Labels: map[string]string{
+						capiv1beta1.ClusterNameLabel: clusterName,
+					},
+				},
+				Type: capiv1beta1.ClusterSecretType,
+				Data: map[string][]byte{},
+			}
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: clusterNamespace}})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &controlPlane)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &cluster)).To(Succeed())
+			kubeconfig.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion:         controlPlane.GetAPIVersion(),
+					BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+					Controller:         func(v bool) *bool { return &v }(true),
+					Kind:               controlPlane.GetKind(),
+					Name:               controlPlane.GetName(),
+					UID:                controlPlane.GetUID(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &controlPlane)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &cluster)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		It("should return the proper name for the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			By("providing a namespaced cluster object")
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+
+			By("asserting that the name is correct")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespacedName).To(Equal(types.NamespacedName{Name: kubeconfig.Name, Namespace: kubeconfig.Namespace}))
+		})
+
+		It("should validate the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+
+			By("providing a kubeconfig secret object")
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, &cluster)
+
+			By("asserting that the secret is an AKS kubeconfig")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+		})
+	})
+
+	When("handling an AKS cluster", func() {
+		var clusterName = "aks-cluster"
+		var clusterNamespace = "aks-cluster-namespace"
+
+		// Unexpected secret name
+		var badConfig1 = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-user-kubeconfig",
+				Namespace: clusterNamespace,
+				Labels: map[string]string{
+					capiv1beta1.ClusterNameLabel: clusterName,
+				},
+			},
+			Type: capiv1beta1.ClusterSecretType,
+			Data: map[string][]byte{},
+		}
+
+		// Missing label
+		var badConfig2 = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-kubeconfig",
+				Namespace: clusterNamespace,
+			},
+			Type: capiv1beta1.ClusterSecretType,
+			Data: map[string][]byte{},
+		}
+
+		It("should reject all invalid kubeconfigs", func() {
+			var p = azureManagedControlPlane{
+				Client:           k8sClient,
+				APIVersion:       v1beta1AzureManagedControlPlane,
+				ControlPlaneName: clusterName + "-control-plane",
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			By("providing kubeconfig secrets with bad configs")
+			Expect(p.IsKubeconfig(ctx, &badConfig1)).To(BeFalse())
+			Expect(p.IsKubeconfig(ctx, &badConfig2)).To(BeFalse())
+		})
+
+		It("should reject unsupported API versions", func() {
+			var p = azureManagedControlPlane{
+				Client:           k8sClient,
+				APIVersion:       "infrastructure.cluster.x-k8s.io/v1alpha4",
+				ControlPlaneName: clusterName + "-control-plane",
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			By("trying to validate the kubeconfig")
+			Expect(p.IsKubeconfig(ctx, &badConfig2)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,117 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// controlPlaneGroup is the API group shared by most Cluster API control
+// plane providers.
+const controlPlaneGroup = "controlplane.cluster.x-k8s.io"
+
+// ownership describes how a kubeconfig secret must be owned by its control
+// plane object.
+type ownership int
+
+const (
+	// controllerOwnership requires the control plane to be the managing
+	// controller of the secret.
+	controllerOwnership ownership = iota
+
+	// ownerOwnership requires the control plane to be one of the owners
+	// of the secret.
+	ownerOwnership
+)
+
+// isClusterKubeconfig determines whether the secret provided follows the
+// Cluster API kubeconfig secret contract for the given cluster.
+func isClusterKubeconfig(ctx context.Context, secret *corev1.Secret, clusterName string) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	if secret.Type != capiv1beta1.ClusterSecretType {
+		logger.V(4).Info("Secret is not a cluster secret",
+			"secret namespace", secret.GetNamespace(),
+			"secret name", secret.GetName(),
+			"secret type", secret.Type,
+		)
+		return false
+	}
+	name := secret.Labels[capiv1beta1.ClusterNameLabel]
+	if name != clusterName {
+		logger.V(4).Info("Secret cluster name label does not contain cluster name",
+			"secret namespace", secret.GetNamespace(),
+			"secret name", secret.GetName(),
+			"cluster label name", name,
+		)
+		return false
+	}
+	return true
+}
+
+// isOwnedByControlPlane determines whether the secret provided is owned by
+// the control plane object with the given API version, kind, namespace and
+// name. The control plane is retrieved as an unstructured object, so that
+// providers do not need to import the Go types of every control plane.
+func isOwnedByControlPlane(ctx context.Context, c client.Client, secret *corev1.Secret, apiVersion, kind, namespace, name string, o ownership) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	cp := unstructured.Unstructured{}
+	cp.SetAPIVersion(apiVersion)
+	cp.SetKind(kind)
+	cp.SetNamespace(namespace)
+	cp.SetName(name)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&cp), &cp, &client.GetOptions{}); err != nil {
+		logger.V(4).Info("Could not find "+kind+" object for secret",
+			"secret namespace", secret.GetNamespace(),
+			"secret name", secret.GetName(),
+			"error", err,
+		)
+		return false
+	}
+	switch o {
+	case controllerOwnership:
+		if metav1.IsControlledBy(secret, &cp) {
+			return true
+		}
+	case ownerOwnership:
+		for _, or := range secret.GetOwnerReferences() {
+			if or.UID == cp.GetUID() {
+				return true
+			}
+		}
+	}
+	logger.V(4).Info("Secret is not owned by "+kind,
+		"secret namespace", secret.GetNamespace(),
+		"secret name", secret.GetName(),
+		"owner references", secret.GetOwnerReferences(),
+	)
+	return false
+}
+
+// getServiceServer returns the in-cluster address of the API server exposed
+// by the Service with the given namespace and name. The port named portName
+// is preferred, and the first port of the Service is used otherwise.
+func getServiceServer(ctx context.Context, c client.Client, name, namespace, portName string) (string, error) {
+	svc := corev1.Service{}
+	key := types.NamespacedName{Name: name, Namespace: namespace}
+	if err := c.Get(ctx, key, &svc, &client.GetOptions{}); err != nil {
+		return "", fmt.Errorf("could not get API server service %s: %w", key, err)
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return "", fmt.Errorf("API server service %s exposes no ports", key)
+	}
+	port := svc.Spec.Ports[0].Port
+	for _, p := range svc.Spec.Ports {
+		if p.Name == portName {
+			port = p.Port
+		}
+	}
+	return fmt.Sprintf("https://%s.%s.svc:%d", svc.Name, svc.Namespace, port), nil
+}
@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const v1beta1GCPManagedControlPlane = "infrastructure.cluster.x-k8s.io/v1beta1"
+
+// gcpManagedControlPlane handles GKE clusters managed by CAPG. CAPG stores
+// a kubeconfig with a short-lived access token in "<cluster>-kubeconfig", and
+// one relying on the gke-gcloud-auth-plugin in "<cluster>-user-kubeconfig".
+// The latter is used, as its exec plugin is converted into one that ArgoCD
+// runs with its own credentials, whereas the token would expire long before
+// the cluster is reconciled again.
+type gcpManagedControlPlane struct {
+	client.Client
+	ControlPlaneName string
+	ClusterName      string
+	Namespace        string
+	APIVersion       string
+}
+
+// GetNamespacedName returns the namespace and name of a cluster
+// with a GCP managed control plane.
+func (g gcpManagedControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-user-kubeconfig", g.ClusterName),
+		Namespace: g.Namespace,
+	}
+}
+
+// IsKubeconfig determines whether the secret provided is a
+// GCPManagedControlPlane kubeconfig or not.
+func (g gcpManagedControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	switch g.APIVersion {
+	case v1beta1GCPManagedControlPlane:
+		if secret.Name != fmt.Sprintf("%s-user-kubeconfig", g.ClusterName) {
+			logger.V(4).Info("Secret does not match '*-user-kubeconfig' pattern",
+				"secret namespace", secret.GetNamespace(),
+				"secret name", secret.GetName(),
+			)
+			return false
+		}
+		if !isClusterKubeconfig(ctx, secret, g.ClusterName) {
+			return false
+		}
+		return isOwnedByControlPlane(ctx, g.Client, secret,
+			g.APIVersion, string(gcpManagedKind), g.Namespace, g.ControlPlaneName,
+			controllerOwnership,
+		)
+	default:
+		logger.V(2).Info("APIVersion unsupported for GCPManagedControlPlane",
+			"APIVersion", g.APIVersion,
+		)
+		return false
+	}
+}
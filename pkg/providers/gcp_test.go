@@ -0,0 +1,189 @@
+package providers
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("GCP provider tests", func() {
+	When("handling a GKE cluster with its control plane", func() {
+		var clusterName = "gke-cluster"
+		var clusterNamespace = "gke-cluster-namespace"
+		var controlPlane = unstructured.Unstructured{}
+		var cluster = capiv1beta1.Cluster{}
+		var kubeconfig = corev1.Secret{}
+		var userKubeconfig = corev1.Secret{}
+
+		BeforeEach(func() {
+			clusterNamespace = fmt.Sprintf("%s-%d", clusterNamespace, time.Now().UnixMilli())
+			controlPlane = unstructured.Unstructured{}
+			controlPlane.SetAPIVersion(v1beta1GCPManagedControlPlane)
+			controlPlane.SetKind("GCPManagedControlPlane")
+			controlPlane.SetName(clusterName + "-control-plane")
+			controlPlane.SetNamespace(clusterNamespace)
+			cluster = capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName,
+					Namespace: clusterNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Name:       controlPlane.GetName(),
+						Namespace:  controlPlane.GetNamespace(),
+						APIVersion: v1beta1GCPManagedControlPlane,
+						Kind:       "GCPManagedControlPlane",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: clusterNamespace}})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &controlPlane)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &cluster)).To(Succeed())
+
+			// CAPG writes both kubeconfigs with the same owner and label.
+			for _, secret := range []*corev1.Secret{&kubeconfig, &userKubeconfig} {
+				*secret = corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: clusterNamespace,
+						Labels: map[string]string{
+							capiv1beta1.ClusterNameLabel: clusterName,
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion:         controlPlane.GetAPIVersion(),
+								BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+								Controller:         func(v bool) *bool { return &v }(true),
+								Kind:               controlPlane.GetKind(),
+								Name:               controlPlane.GetName(),
+								UID:                controlPlane.GetUID(),
+							},
+						},
+					},
+					Type: capiv1beta1.ClusterSecretType,
+					Data: map[string][]byte{},
+				}
+			}
+			kubeconfig.Name = clusterName + "-kubeconfig"
+			userKubeconfig.Name = clusterName + "-user-kubeconfig"
+			Expect(k8sClient.Create(ctx, &kubeconfig)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &userKubeconfig)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &controlPlane)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &cluster)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &kubeconfig)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &userKubeconfig)).To(Succeed())
+		})
+
+		It("should return the user kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			By("providing a namespaced cluster object")
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+
+			By("asserting that the name is the one of the user kubeconfig")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespacedName).To(Equal(types.NamespacedName{Name: userKubeconfig.Name, Namespace: userKubeconfig.Namespace}))
+		})
+
+		It("should only validate the user kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+
+			By("providing the user kubeconfig secret")
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, &userKubeconfig, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+
+			By("providing the token-based kubeconfig secret")
+			validated, err = clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeFalse())
+		})
+
+		It("should reject a kubeconfig not controlled by the control plane", func() {
+			var p = gcpManagedControlPlane{
+				Client:           k8sClient,
+				APIVersion:       v1beta1GCPManagedControlPlane,
+				ControlPlaneName: controlPlane.GetName(),
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			secret := userKubeconfig.DeepCopy()
+			secret.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion: "fake.io/v1alpha1",
+					Kind:       "Fake",
+					Name:       "FakeObject",
+				},
+			}
+
+			By("providing a kubeconfig secret owned by another object")
+			Expect(p.IsKubeconfig(ctx, secret)).To(BeFalse())
+		})
+	})
+
+	When("handling a GKE cluster", func() {
+		var clusterName = "gke-cluster"
+		var clusterNamespace = "gke-cluster-namespace"
+
+		// Missing secret type
+		var badConfig1 = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-user-kubeconfig",
+				Namespace: clusterNamespace,
+				Labels: map[string]string{
+					capiv1beta1.ClusterNameLabel: clusterName,
+				},
+			},
+			Data: map[string][]byte{},
+		}
+
+		// Missing label
+		var badConfig2 = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-user-kubeconfig",
+				Namespace: clusterNamespace,
+			},
+			Type: capiv1beta1.ClusterSecretType,
+			Data: map[string][]byte{},
+		}
+
+		It("should reject all invalid kubeconfigs", func() {
+			var p = gcpManagedControlPlane{
+				Client:           k8sClient,
+				APIVersion:       v1beta1GCPManagedControlPlane,
+				ControlPlaneName: clusterName + "-control-plane",
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			By("providing kubeconfig secrets with bad configs")
+			Expect(p.IsKubeconfig(ctx, &badConfig1)).To(BeFalse())
+			Expect(p.IsKubeconfig(ctx, &badConfig2)).To(BeFalse())
+		})
+
+		It("should reject unsupported API versions", func() {
+			var p = gcpManagedControlPlane{
+				Client:           k8sClient,
+				APIVersion:       "infrastructure.cluster.x-k8s.io/v1alpha4",
+				ControlPlaneName: clusterName + "-control-plane",
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			By("trying to validate the kubeconfig")
+			Expect(p.IsKubeconfig(ctx, &badConfig2)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// genericControlPlane handles control planes unknown to capargo, relying
+// solely on the Cluster API contract for kubeconfig secrets: a secret named
+// "<cluster>-kubeconfig" in the namespace of the cluster, with the
+// "cluster.x-k8s.io/secret" type and the cluster name label.
+type genericControlPlane struct {
+	ClusterName string
+	Namespace   string
+}
+
+// GetNamespacedName returns the namespace and name of the kubeconfig of a
+// cluster following the Cluster API contract.
+func (g genericControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-kubeconfig", g.ClusterName),
+		Namespace: g.Namespace,
+	}
+}
+
+// IsKubeconfig determines whether the secret provided is a Cluster API
+// kubeconfig or not.
+func (g genericControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	if secret.Namespace != g.Namespace {
+		logger.V(4).Info("Secret is not in the same namespace as the cluster",
+			"secret namespace", secret.GetNamespace(),
+			"secret name", secret.GetName(),
+		)
+		return false
+	}
+	if secret.Name != fmt.Sprintf("%s-kubeconfig", g.ClusterName) {
+		logger.V(4).Info("Secret does not match '*-kubeconfig' pattern",
+			"secret namespace", secret.GetNamespace(),
+			"secret name", secret.GetName(),
+		)
+		return false
+	}
+	return isClusterKubeconfig(ctx, secret, g.ClusterName)
+}
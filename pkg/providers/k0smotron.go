@@ -0,0 +1,95 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const v1beta1K0smotronControlPlane = "controlplane.cluster.x-k8s.io/v1beta1"
+
+// k0smotronControlPlane handles control planes running as pods in the
+// management cluster. k0smotron attaches the K0smotronControlPlane as a
+// plain owner of the kubeconfig secret rather than as its controller.
+type k0smotronControlPlane struct {
+	client.Client
+	ControlPlaneName string
+	ClusterName      string
+	Namespace        string
+	APIVersion       string
+}
+
+// GetNamespacedName returns the namespace and name of a cluster
+// with a k0smotron hosted control plane.
+func (k k0smotronControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-kubeconfig", k.ClusterName),
+		Namespace: k.Namespace,
+	}
+}
+
+// IsKubeconfig determines whether the secret provided is a
+// K0smotronControlPlane kubeconfig or not.
+func (k k0smotronControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	switch k.APIVersion {
+	case v1beta1K0smotronControlPlane:
+		if !isClusterKubeconfig(ctx, secret, k.ClusterName) {
+			return false
+		}
+		return isOwnedByControlPlane(ctx, k.Client, secret,
+			k.APIVersion, string(k0smotronKind), k.Namespace, k.ControlPlaneName,
+			ownerOwnership,
+		)
+	default:
+		logger.V(2).Info("APIVersion unsupported for K0smotronControlPlane",
+			"APIVersion", k.APIVersion,
+		)
+		return false
+	}
+}
+
+// k0sControlPlane handles control planes bootstrapped with k0s on machines
+// managed by Cluster API.
+type k0sControlPlane struct {
+	client.Client
+	ControlPlaneName string
+	ClusterName      string
+	Namespace        string
+	APIVersion       string
+}
+
+// GetNamespacedName returns the namespace and name of a cluster
+// with a k0s-bootstrapped control plane.
+func (k k0sControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-kubeconfig", k.ClusterName),
+		Namespace: k.Namespace,
+	}
+}
+
+// IsKubeconfig determines whether the secret provided is a
+// K0sControlPlane kubeconfig or not.
+func (k k0sControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	switch k.APIVersion {
+	case v1beta1K0smotronControlPlane:
+		if !isClusterKubeconfig(ctx, secret, k.ClusterName) {
+			return false
+		}
+		return isOwnedByControlPlane(ctx, k.Client, secret,
+			k.APIVersion, string(k0sKind), k.Namespace, k.ControlPlaneName,
+			controllerOwnership,
+		)
+	default:
+		logger.V(2).Info("APIVersion unsupported for K0sControlPlane",
+			"APIVersion", k.APIVersion,
+		)
+		return false
+	}
+}
@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("k0smotron provider tests", func() {
+	When("handling k0s clusters with a non-controller owner reference", func() {
+		var clusterName = "k0s-cluster"
+		var clusterNamespace = "k0s-cluster-namespace"
+		var k0smotronCP = unstructured.Unstructured{}
+		var k0sCP = unstructured.Unstructured{}
+		var kubeconfig = corev1.Secret{}
+
+		BeforeEach(func() {
+			clusterNamespace = fmt.Sprintf("%s-%d", clusterNamespace, time.Now().UnixMilli())
+			k0smotronCP = unstructured.Unstructured{}
+			k0smotronCP.SetAPIVersion(v1beta1K0smotronControlPlane)
+			k0smotronCP.SetKind("K0smotronControlPlane")
+			k0smotronCP.SetName(clusterName + "-k0smotron")
+			k0smotronCP.SetNamespace(clusterNamespace)
+			k0sCP = unstructured.Unstructured{}
+			k0sCP.SetAPIVersion(v1beta1K0smotronControlPlane)
+			k0sCP.SetKind("K0sControlPlane")
+			k0sCP.SetName(clusterName + "-k0s")
+			k0sCP.SetNamespace(clusterNamespace)
+			kubeconfig = corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName + "-kubeconfig",
+					Namespace: clusterNamespace,
+					Labels: map[string]string{
+						capiv1beta1.ClusterNameLabel: clusterName,
+					},
+				},
+				Type: capiv1beta1.ClusterSecretType,
+				Data: map[string][]byte{},
+			}
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: clusterNamespace}})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &k0smotronCP)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &k0sCP)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &kubeconfig)).To(Succeed())
+			kubeconfig.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion: k0smotronCP.GetAPIVersion(),
+					Kind:       k0smotronCP.GetKind(),
+					Name:       k0smotronCP.GetName(),
+					UID:        k0smotronCP.GetUID(),
+				},
+				{
+					APIVersion: k0sCP.GetAPIVersion(),
+					Kind:       k0sCP.GetKind(),
+					Name:       k0sCP.GetName(),
+					UID:        k0sCP.GetUID(),
+				},
+			}
+			Expect(k8sClient.Update(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &k0smotronCP)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &k0sCP)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		It("should validate the kubeconfig for a K0smotronControlPlane", func() {
+			var p = k0smotronControlPlane{
+				Client:           k8sClient,
+				APIVersion:       v1beta1K0smotronControlPlane,
+				ControlPlaneName: k0smotronCP.GetName(),
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			By("providing a kubeconfig secret owned by the control plane")
+			Expect(p.IsKubeconfig(ctx, &kubeconfig)).To(BeTrue())
+		})
+
+		It("should reject the kubeconfig for a K0sControlPlane", func() {
+			var p = k0sControlPlane{
+				Client:           k8sClient,
+				APIVersion:       v1beta1K0smotronControlPlane,
+				ControlPlaneName: k0sCP.GetName(),
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			By("providing a kubeconfig secret not controlled by the control plane")
+			Expect(p.IsKubeconfig(ctx, &kubeconfig)).To(BeFalse())
+		})
+
+		It("should reject unsupported API versions", func() {
+			var p = k0smotronControlPlane{
+				Client:           k8sClient,
+				APIVersion:       "controlplane.cluster.x-k8s.io/v1alpha1",
+				ControlPlaneName: k0smotronCP.GetName(),
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			By("trying to validate the kubeconfig")
+			Expect(p.IsKubeconfig(ctx, &kubeconfig)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,55 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	v1beta1KThreesControlPlane = "controlplane.cluster.x-k8s.io/v1beta1"
+	v1beta2KThreesControlPlane = "controlplane.cluster.x-k8s.io/v1beta2"
+)
+
+type kThreesControlPlane struct {
+	client.Client
+	ControlPlaneName string
+	ClusterName      string
+	Namespace        string
+	APIVersion       string
+}
+
+// GetNamespacedName returns the namespace and name of a cluster
+// with a K3s-bootstrapped control plane.
+func (k kThreesControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-kubeconfig", k.ClusterName),
+		Namespace: k.Namespace,
+	}
+}
+
+// IsKubeconfig determines whether the secret provided is a
+// KThreesControlPlane kubeconfig or not.
+func (k kThreesControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	switch k.APIVersion {
+	case v1beta1KThreesControlPlane, v1beta2KThreesControlPlane:
+		if !isClusterKubeconfig(ctx, secret, k.ClusterName) {
+			return false
+		}
+		return isOwnedByControlPlane(ctx, k.Client, secret,
+			k.APIVersion, string(kThreesKind), k.Namespace, k.ControlPlaneName,
+			controllerOwnership,
+		)
+	default:
+		logger.V(2).Info("APIVersion unsupported for KThreesControlPlane",
+			"APIVersion", k.APIVersion,
+		)
+		return false
+	}
+}
@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("K3s provider tests", func() {
+	When("handling a K3s cluster with its control plane", func() {
+		var clusterName = "k3s-cluster"
+		var clusterNamespace = "k3s-cluster-namespace"
+		var controlPlane = unstructured.Unstructured{}
+		var kubeconfig = corev1.Secret{}
+
+		// newCluster returns a cluster referencing the control plane with
+		// the given API version.
+		newCluster := func(apiVersion string) *capiv1beta1.Cluster {
+			return &capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName,
+					Namespace: clusterNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Name:       controlPlane.GetName(),
+						Namespace:  controlPlane.GetNamespace(),
+						APIVersion: apiVersion,
+						Kind:       "KThreesControlPlane",
+					},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			clusterNamespace = fmt.Sprintf("%s-%d", clusterNamespace, time.Now().UnixMilli())
+			controlPlane = unstructured.Unstructured{}
+			controlPlane.SetAPIVersion(v1beta2KThreesControlPlane)
+			controlPlane.SetKind("KThreesControlPlane")
+			controlPlane.SetName(clusterName + "-control-plane")
+			controlPlane.SetNamespace(clusterNamespace)
+			kubeconfig = corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName + "-kubeconfig",
+					Namespace: clusterNamespace,
+					Labels: map[string]string{
+						capiv1beta1.ClusterNameLabel: clusterName,
+					},
+				},
+				Type: capiv1beta1.ClusterSecretType,
+				Data: map[string][]byte{},
+			}
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: clusterNamespace}})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &controlPlane)).To(Succeed())
+			kubeconfig.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion:         controlPlane.GetAPIVersion(),
+					BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+					Controller:         func(v bool) *bool { return &v }(true),
+					Kind:               controlPlane.GetKind(),
+					Name:               controlPlane.GetName(),
+					UID:                controlPlane.GetUID(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &controlPlane)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		It("should validate the kubeconfig of a v1beta2 control plane", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			cluster := newCluster(v1beta2KThreesControlPlane)
+
+			By("providing a namespaced cluster object")
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespacedName).To(Equal(types.NamespacedName{Name: kubeconfig.Name, Namespace: kubeconfig.Namespace}))
+
+			By("providing a kubeconfig secret object")
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+		})
+
+		It("should validate the kubeconfig of a cluster still referencing v1beta1", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			// The control plane is stored as v1beta2, and its secret is
+			// owned through that version.
+			cluster := newCluster(v1beta1KThreesControlPlane)
+
+			By("providing a kubeconfig secret object")
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+		})
+
+		It("should reject a kubeconfig not controlled by the control plane", func() {
+			var p = kThreesControlPlane{
+				Client:           k8sClient,
+				APIVersion:       v1beta2KThreesControlPlane,
+				ControlPlaneName: controlPlane.GetName(),
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			secret := kubeconfig.DeepCopy()
+			secret.OwnerReferences[0].Controller = nil
+
+			By("providing a kubeconfig secret only owned by the control plane")
+			Expect(p.IsKubeconfig(ctx, secret)).To(BeFalse())
+		})
+
+		It("should reject unsupported API versions", func() {
+			var p = kThreesControlPlane{
+				Client:           k8sClient,
+				APIVersion:       "controlplane.cluster.x-k8s.io/v1alpha1",
+				ControlPlaneName: controlPlane.GetName(),
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			By("trying to validate the kubeconfig")
+			Expect(p.IsKubeconfig(ctx, &kubeconfig)).To(BeFalse())
+		})
+	})
+})
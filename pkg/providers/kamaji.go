@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	v1alpha1KamajiControlPlane = "controlplane.cluster.x-k8s.io/v1alpha1"
+	v1alpha1TenantControlPlane = "kamaji.clastix.io/v1alpha1"
+	tenantControlPlaneKind     = "TenantControlPlane"
+)
+
+// kamajiControlPlane handles control planes hosted by Kamaji. The Kamaji
+// control plane provider creates a TenantControlPlane with the same name as
+// the KamajiControlPlane, and Kamaji stores the admin kubeconfig of the
+// tenant in the "<name>-admin-kubeconfig" secret under "admin.conf".
+type kamajiControlPlane struct {
+	client.Client
+	Name       string
+	Namespace  string
+	APIVersion string
+}
+
+// GetNamespacedName returns the namespace and name of a kubeconfig with a
+// Kamaji control plane.
+func (k kamajiControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-admin-kubeconfig", k.Name),
+		Namespace: k.Namespace,
+	}
+}
+
+// GetKubeconfigKey returns the key of the kubeconfig within the Kamaji admin
+// kubeconfig secret.
+func (k kamajiControlPlane) GetKubeconfigKey() string {
+	return "admin.conf"
+}
+
+// IsKubeconfig determines whether the secret provided is a Kamaji
+// kubeconfig or not.
+func (k kamajiControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	switch k.APIVersion {
+	case v1alpha1KamajiControlPlane:
+		if secret.Name != fmt.Sprintf("%s-admin-kubeconfig", k.Name) {
+			logger.V(4).Info("Secret does not match '*-admin-kubeconfig' pattern",
+				"secret namespace", secret.GetNamespace(),
+				"secret name", secret.GetName(),
+			)
+			return false
+		}
+		return isOwnedByControlPlane(ctx, k.Client, secret,
+			v1alpha1TenantControlPlane, tenantControlPlaneKind, k.Namespace, k.Name,
+			controllerOwnership,
+		)
+	default:
+		logger.V(2).Info("APIVersion unsupported for KamajiControlPlane",
+			"APIVersion", k.APIVersion,
+		)
+		return false
+	}
+}
+
+// GetInClusterServer returns the address of the Service exposing the
+// tenant API server within the management cluster.
+func (k kamajiControlPlane) GetInClusterServer(ctx context.Context) (string, error) {
+	return getServiceServer(ctx, k.Client, k.Name, k.Namespace, "kube-apiserver")
+}
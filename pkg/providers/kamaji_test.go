@@ -0,0 +1,158 @@
+package providers
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("Kamaji provider tests", func() {
+	When("handling a supported Kamaji cluster", func() {
+		var clusterName = "kamaji-cluster"
+		var clusterNamespace = "kamaji-cluster-namespace"
+		var tenantControlPlane = unstructured.Unstructured{}
+		var cluster = capiv1beta1.Cluster{}
+		var kubeconfig = corev1.Secret{}
+		var service = corev1.Service{}
+
+		BeforeEach(func() {
+			clusterNamespace = fmt.Sprintf("%s-%d", clusterNamespace, time.Now().UnixMilli())
+			tenantControlPlane = unstructured.Unstructured{}
+			tenantControlPlane.SetAPIVersion(v1alpha1TenantControlPlane)
+			tenantControlPlane.SetKind(tenantControlPlaneKind)
+			tenantControlPlane.SetName(clusterName + "-control-plane")
+			tenantControlPlane.SetNamespace(clusterNamespace)
+			cluster = capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName,
+					Namespace: clusterNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Name:       tenantControlPlane.GetName(),
+						Namespace:  clusterNamespace,
+						APIVersion: "controlplane.cluster.x-k8s.io/v1alpha1",
+						Kind:       "KamajiControlPlane",
+					},
+				},
+			}
+			kubeconfig = corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tenantControlPlane.GetName() + "-admin-kubeconfig",
+					Namespace: clusterNamespace,
+				},
+				Data: map[string][]byte{},
+			}
+			service = corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tenantControlPlane.GetName(),
+					Namespace: clusterNamespace,
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Name: "kube-apiserver",
+							Port: 6443,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: clusterNamespace}})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &tenantControlPlane)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &cluster)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &service)).To(Succeed())
+			kubeconfig.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion:         tenantControlPlane.GetAPIVersion(),
+					BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+					Controller:         func(v bool) *bool { return &v }(true),
+					Kind:               tenantControlPlane.GetKind(),
+					Name:               tenantControlPlane.GetName(),
+					UID:                tenantControlPlane.GetUID(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &tenantControlPlane)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &cluster)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &service)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		It("should return the proper name and key for the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			By("providing a namespaced cluster object")
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			key, err := clusterProvider.GetCapiKubeconfigKey(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("asserting that the name and key are correct")
+			Expect(namespacedName).To(Equal(types.NamespacedName{Name: kubeconfig.Name, Namespace: kubeconfig.Namespace}))
+			Expect(key).To(Equal("admin.conf"))
+		})
+
+		It("should validate the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+
+			By("providing a kubeconfig secret object")
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, &cluster)
+
+			By("asserting that the secret is a Kamaji kubeconfig")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+		})
+
+		It("should return the in-cluster server address", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+
+			By("providing a namespaced cluster object")
+			server, err := clusterProvider.GetCapiInClusterServer(ctx, &cluster)
+
+			By("asserting that the address points at the tenant service")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server).To(Equal(fmt.Sprintf("https://%s.%s.svc:6443", service.Name, clusterNamespace)))
+		})
+	})
+
+	When("handling an unsupported Kamaji cluster", func() {
+		var clusterName = "kamaji-cluster"
+		var clusterNamespace = "kamaji-cluster-namespace"
+
+		var kubeconfig = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-admin-kubeconfig",
+				Namespace: clusterNamespace,
+			},
+			Data: map[string][]byte{},
+		}
+
+		It("should reject the unsupported cluster", func() {
+			var p = kamajiControlPlane{
+				Client:     k8sClient,
+				APIVersion: "controlplane.cluster.x-k8s.io/v1beta1",
+				Name:       clusterName,
+				Namespace:  clusterNamespace,
+			}
+			By("trying to validate the kubeconfig")
+			Expect(p.IsKubeconfig(ctx, &kubeconfig)).To(BeFalse())
+		})
+	})
+})
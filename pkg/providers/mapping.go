@@ -0,0 +1,178 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	capargov1alpha1 "github.com/superorbital/capargo/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// mappingObject holds the fields of an object available to the secret name
+// template of a KubeconfigSourceMapping.
+type mappingObject struct {
+	Name      string
+	Namespace string
+}
+
+// mappingTemplateData is the data given to the secret name template of a
+// KubeconfigSourceMapping.
+type mappingTemplateData struct {
+	Cluster      mappingObject
+	ControlPlane mappingObject
+}
+
+// mappingControlPlane handles control planes declared through a
+// KubeconfigSourceMapping.
+type mappingControlPlane struct {
+	client.Client
+	Mapping          capargov1alpha1.KubeconfigSourceMapping
+	SecretName       string
+	ControlPlaneName string
+	ClusterName      string
+	Namespace        string
+	APIVersion       string
+	Kind             string
+}
+
+// GetNamespacedName returns the namespace and name of the kubeconfig
+// rendered from the secret name template of the mapping.
+func (m mappingControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      m.SecretName,
+		Namespace: m.Namespace,
+	}
+}
+
+// GetKubeconfigKey returns the key of the kubeconfig declared by the
+// mapping.
+func (m mappingControlPlane) GetKubeconfigKey() string {
+	if m.Mapping.Spec.Key == "" {
+		return defaultKubeconfigKey
+	}
+	return m.Mapping.Spec.Key
+}
+
+// IsKubeconfig determines whether the secret provided satisfies the rules of
+// the mapping or not.
+func (m mappingControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	spec := m.Mapping.Spec
+	if secret.Namespace != m.Namespace || secret.Name != m.SecretName {
+		logger.V(4).Info("Secret does not match the KubeconfigSourceMapping secret name",
+			"secret namespace", secret.GetNamespace(),
+			"secret name", secret.GetName(),
+			"mapping", m.Mapping.Name,
+		)
+		return false
+	}
+	if spec.SecretType != "" && string(secret.Type) != spec.SecretType {
+		logger.V(4).Info("Secret does not match the KubeconfigSourceMapping secret type",
+			"secret namespace", secret.GetNamespace(),
+			"secret name", secret.GetName(),
+			"secret type", secret.Type,
+			"mapping", m.Mapping.Name,
+		)
+		return false
+	}
+	if spec.RequireClusterNameLabel && secret.Labels[capiv1beta1.ClusterNameLabel] != m.ClusterName {
+		logger.V(4).Info("Secret cluster name label does not contain cluster name",
+			"secret namespace", secret.GetNamespace(),
+			"secret name", secret.GetName(),
+			"cluster label name", secret.Labels[capiv1beta1.ClusterNameLabel],
+		)
+		return false
+	}
+	switch spec.Ownership {
+	case capargov1alpha1.OwnershipNone:
+		return true
+	case capargov1alpha1.OwnershipOwner:
+		return isOwnedByControlPlane(ctx, m.Client, secret,
+			m.APIVersion, m.Kind, m.Namespace, m.ControlPlaneName,
+			ownerOwnership,
+		)
+	default:
+		return isOwnedByControlPlane(ctx, m.Client, secret,
+			m.APIVersion, m.Kind, m.Namespace, m.ControlPlaneName,
+			controllerOwnership,
+		)
+	}
+}
+
+// getMappingProvider returns a provider built from the
+// KubeconfigSourceMapping matching the control plane of the cluster, or nil
+// if there is none.
+func (c *ClusterProvider) getMappingProvider(ctx context.Context, cluster *capiv1beta1.Cluster) (Provider, error) {
+	ref := cluster.Spec.ControlPlaneRef
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("controlPlaneRef apiVersion %s invalid: %w", ref.APIVersion, err)
+	}
+	mappings := capargov1alpha1.KubeconfigSourceMappingList{}
+	if err := c.List(ctx, &mappings); err != nil {
+		// The mappings are optional, and so is their CRD.
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not list KubeconfigSourceMappings: %w", err)
+	}
+	for _, mapping := range mappings.Items {
+		cp := mapping.Spec.ControlPlane
+		if cp.Group != gv.Group || cp.Kind != ref.Kind {
+			continue
+		}
+		if len(cp.Versions) > 0 && !slices.Contains(cp.Versions, gv.Version) {
+			continue
+		}
+		secretName, err := renderSecretName(mapping, cluster)
+		if err != nil {
+			return nil, err
+		}
+		var p Provider = mappingControlPlane{
+			Client:           c.Client,
+			Mapping:          mapping,
+			SecretName:       secretName,
+			ClusterName:      cluster.Name,
+			APIVersion:       ref.APIVersion,
+			Kind:             ref.Kind,
+			ControlPlaneName: ref.Name,
+			Namespace:        ref.Namespace,
+		}
+		return p, nil
+	}
+	return nil, nil
+}
+
+// renderSecretName renders the secret name template of a mapping for the
+// given cluster.
+func renderSecretName(mapping capargov1alpha1.KubeconfigSourceMapping, cluster *capiv1beta1.Cluster) (string, error) {
+	tmpl, err := template.New(mapping.Name).Option("missingkey=error").Parse(mapping.Spec.SecretNameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("could not parse secret name template of KubeconfigSourceMapping %s: %w", mapping.Name, err)
+	}
+	data := mappingTemplateData{
+		Cluster: mappingObject{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+		ControlPlane: mappingObject{
+			Name:      cluster.Spec.ControlPlaneRef.Name,
+			Namespace: cluster.Spec.ControlPlaneRef.Namespace,
+		},
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("could not render secret name template of KubeconfigSourceMapping %s: %w", mapping.Name, err)
+	}
+	return b.String(), nil
+}
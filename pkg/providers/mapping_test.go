@@ -0,0 +1,170 @@
+package providers
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	capargov1alpha1 "github.com/superorbital/capargo/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("KubeconfigSourceMapping provider tests", func() {
+	When("a mapping exists for the control plane of a cluster", func() {
+		var clusterName = "custom-cluster"
+		var clusterNamespace = "custom-cluster-namespace"
+		var mapping = capargov1alpha1.KubeconfigSourceMapping{}
+		var cluster = capiv1beta1.Cluster{}
+		var kubeconfig = corev1.Secret{}
+
+		BeforeEach(func() {
+			clusterNamespace = fmt.Sprintf("%s-%d", clusterNamespace, time.Now().UnixMilli())
+			mapping = capargov1alpha1.KubeconfigSourceMapping{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "custom-control-plane",
+				},
+				Spec: capargov1alpha1.KubeconfigSourceMappingSpec{
+					ControlPlane: capargov1alpha1.ControlPlaneSelector{
+						Group:    "controlplane.example.com",
+						Kind:     "CustomControlPlane",
+						Versions: []string{"v1alpha1"},
+					},
+					SecretNameTemplate:      "{{ .ControlPlane.Name }}-admin",
+					Key:                     "kubeconfig",
+					SecretType:              string(capiv1beta1.ClusterSecretType),
+					RequireClusterNameLabel: true,
+					Ownership:               capargov1alpha1.OwnershipNone,
+				},
+			}
+			cluster = capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName,
+					Namespace: clusterNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						APIVersion: "controlplane.example.com/v1alpha1",
+						Kind:       "CustomControlPlane",
+						Name:       clusterName + "-control-plane",
+						Namespace:  clusterNamespace,
+					},
+				},
+			}
+			kubeconfig = corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName + "-control-plane-admin",
+					Namespace: clusterNamespace,
+					Labels: map[string]string{
+						capiv1beta1.ClusterNameLabel: clusterName,
+					},
+				},
+				Type: capiv1beta1.ClusterSecretType,
+				Data: map[string][]byte{},
+			}
+			Expect(k8sClient.Create(ctx, &mapping)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &mapping)).To(Succeed())
+		})
+
+		It("should return the rendered name and key for the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			By("providing a namespaced cluster object")
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			key, err := clusterProvider.GetCapiKubeconfigKey(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("asserting that the name and key are correct")
+			Expect(namespacedName).To(Equal(types.NamespacedName{Name: kubeconfig.Name, Namespace: kubeconfig.Namespace}))
+			Expect(key).To(Equal("kubeconfig"))
+		})
+
+		It("should validate the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+
+			By("providing a kubeconfig secret object")
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+
+			By("providing a kubeconfig secret object without the cluster name label")
+			unlabelled := kubeconfig.DeepCopy()
+			unlabelled.Labels = nil
+			validated, err = clusterProvider.IsCapiKubeconfig(ctx, unlabelled, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeFalse())
+		})
+
+		It("should validate the ownership of the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			controlPlane := unstructured.Unstructured{}
+			controlPlane.SetAPIVersion(cluster.Spec.ControlPlaneRef.APIVersion)
+			controlPlane.SetKind(cluster.Spec.ControlPlaneRef.Kind)
+			controlPlane.SetName(cluster.Spec.ControlPlaneRef.Name)
+			controlPlane.SetNamespace(clusterNamespace)
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: clusterNamespace}})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &controlPlane)).To(Succeed())
+
+			ownerReference := metav1.OwnerReference{
+				APIVersion: controlPlane.GetAPIVersion(),
+				Kind:       controlPlane.GetKind(),
+				Name:       controlPlane.GetName(),
+				UID:        controlPlane.GetUID(),
+			}
+			owned := kubeconfig.DeepCopy()
+			owned.OwnerReferences = []metav1.OwnerReference{ownerReference}
+			controlled := kubeconfig.DeepCopy()
+			ownerReference.Controller = func(v bool) *bool { return &v }(true)
+			controlled.OwnerReferences = []metav1.OwnerReference{ownerReference}
+
+			By("requiring the control plane to be the controller of the secret")
+			mapping.Spec.Ownership = capargov1alpha1.OwnershipController
+			Expect(k8sClient.Update(ctx, &mapping)).To(Succeed())
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, controlled, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+			validated, err = clusterProvider.IsCapiKubeconfig(ctx, owned, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeFalse())
+
+			By("requiring the control plane to be an owner of the secret")
+			mapping.Spec.Ownership = capargov1alpha1.OwnershipOwner
+			Expect(k8sClient.Update(ctx, &mapping)).To(Succeed())
+			validated, err = clusterProvider.IsCapiKubeconfig(ctx, owned, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+			validated, err = clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeFalse())
+
+			Expect(k8sClient.Delete(ctx, &controlPlane)).To(Succeed())
+		})
+
+		It("should not match other API versions", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			other := cluster.DeepCopy()
+			other.Spec.ControlPlaneRef.APIVersion = "controlplane.example.com/v1beta1"
+
+			By("providing a cluster with an unmapped control plane version")
+			_, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, other)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
@@ -13,6 +14,14 @@ import (
 
 const loggerName = "capargo-providers"
 
+// ErrUnsupported is returned for clusters whose control plane is not handled
+// by any provider.
+var ErrUnsupported = errors.New("unsupported")
+
+// defaultKubeconfigKey is the key holding the kubeconfig in a Cluster API
+// kubeconfig secret.
+const defaultKubeconfigKey = "value"
+
 // controlPlaneRefKind
 type controlPlaneRefKind string
 
@@ -25,52 +34,168 @@ const (
 
 	// vclusterKind
 	vclusterKind controlPlaneRefKind = "VCluster"
+
+	// rke2Kind
+	rke2Kind controlPlaneRefKind = "RKE2ControlPlane"
+
+	// kThreesKind
+	kThreesKind controlPlaneRefKind = "KThreesControlPlane"
+
+	// k0smotronKind
+	k0smotronKind controlPlaneRefKind = "K0smotronControlPlane"
+
+	// k0sKind
+	k0sKind controlPlaneRefKind = "K0sControlPlane"
+
+	// kamajiKind
+	kamajiKind controlPlaneRefKind = "KamajiControlPlane"
+
+	// azureManagedKind
+	azureManagedKind controlPlaneRefKind = "AzureManagedControlPlane"
+
+	// gcpManagedKind
+	gcpManagedKind controlPlaneRefKind = "GCPManagedControlPlane"
+
+	// rosaKind
+	rosaKind controlPlaneRefKind = "ROSAControlPlane"
 )
 
 type ClusterProvider struct {
 	client.Client
+	// AllowGenericProvider falls back to the Cluster API kubeconfig secret
+	// contract for control plane kinds that capargo does not know about.
+	AllowGenericProvider bool
 }
 
-type provider interface {
-	GetNamespacedName() types.NamespacedName
-	IsKubeconfig(context.Context, *corev1.Secret) bool
-}
-
-// getProvider returns the provider interface for a given CAPI cluster,
-func (c *ClusterProvider) getProvider(cluster *capiv1beta1.Cluster) (provider, error) {
-	switch controlPlaneRefKind(cluster.Spec.ControlPlaneRef.Kind) {
-	case kubeadmKind:
-		var p provider = kubeadmControlPlane{
-			Client:           c.Client,
+// init registers the providers built into capargo.
+func init() {
+	Register(string(kubeadmKind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return kubeadmControlPlane{
+			Client:           c,
 			ClusterName:      cluster.Name,
 			APIVersion:       cluster.Spec.ControlPlaneRef.APIVersion,
 			ControlPlaneName: cluster.Spec.ControlPlaneRef.Name,
 			Namespace:        cluster.Spec.ControlPlaneRef.Namespace,
 		}
-		return p, nil
-	case awsManagedKind:
-		var p provider = awsManagedControlPlane{
+	})
+	Register(string(awsManagedKind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return awsManagedControlPlane{
+			Client:     c,
 			APIVersion: cluster.Spec.ControlPlaneRef.APIVersion,
 			Name:       cluster.Spec.ControlPlaneRef.Name,
 			Namespace:  cluster.Spec.ControlPlaneRef.Namespace,
 		}
-		return p, nil
-	case vclusterKind:
-		var p provider = vCluster{
+	})
+	Register(string(vclusterKind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return vCluster{
+			Client:     c,
+			APIVersion: cluster.Spec.ControlPlaneRef.APIVersion,
+			Name:       cluster.Spec.ControlPlaneRef.Name,
+			Namespace:  cluster.Spec.ControlPlaneRef.Namespace,
+		}
+	})
+	Register(string(rke2Kind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return rke2ControlPlane{
+			Client:           c,
+			ClusterName:      cluster.Name,
+			APIVersion:       cluster.Spec.ControlPlaneRef.APIVersion,
+			ControlPlaneName: cluster.Spec.ControlPlaneRef.Name,
+			Namespace:        cluster.Spec.ControlPlaneRef.Namespace,
+		}
+	})
+	Register(string(kThreesKind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return kThreesControlPlane{
+			Client:           c,
+			ClusterName:      cluster.Name,
+			APIVersion:       cluster.Spec.ControlPlaneRef.APIVersion,
+			ControlPlaneName: cluster.Spec.ControlPlaneRef.Name,
+			Namespace:        cluster.Spec.ControlPlaneRef.Namespace,
+		}
+	})
+	Register(string(k0smotronKind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return k0smotronControlPlane{
+			Client:           c,
+			ClusterName:      cluster.Name,
+			APIVersion:       cluster.Spec.ControlPlaneRef.APIVersion,
+			ControlPlaneName: cluster.Spec.ControlPlaneRef.Name,
+			Namespace:        cluster.Spec.ControlPlaneRef.Namespace,
+		}
+	})
+	Register(string(k0sKind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return k0sControlPlane{
+			Client:           c,
+			ClusterName:      cluster.Name,
+			APIVersion:       cluster.Spec.ControlPlaneRef.APIVersion,
+			ControlPlaneName: cluster.Spec.ControlPlaneRef.Name,
+			Namespace:        cluster.Spec.ControlPlaneRef.Namespace,
+		}
+	})
+	Register(string(kamajiKind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return kamajiControlPlane{
+			Client:     c,
 			APIVersion: cluster.Spec.ControlPlaneRef.APIVersion,
 			Name:       cluster.Spec.ControlPlaneRef.Name,
 			Namespace:  cluster.Spec.ControlPlaneRef.Namespace,
 		}
+	})
+	Register(string(azureManagedKind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return azureManagedControlPlane{
+			Client:           c,
+			ClusterName:      cluster.Name,
+			APIVersion:       cluster.Spec.ControlPlaneRef.APIVersion,
+			ControlPlaneName: cluster.Spec.ControlPlaneRef.Name,
+			Namespace:        cluster.Spec.ControlPlaneRef.Namespace,
+		}
+	})
+	Register(string(gcpManagedKind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return gcpManagedControlPlane{
+			Client:           c,
+			ClusterName:      cluster.Name,
+			APIVersion:       cluster.Spec.ControlPlaneRef.APIVersion,
+			ControlPlaneName: cluster.Spec.ControlPlaneRef.Name,
+			Namespace:        cluster.Spec.ControlPlaneRef.Namespace,
+		}
+	})
+	Register(string(rosaKind), "", func(c client.Client, cluster *capiv1beta1.Cluster) Provider {
+		return rosaControlPlane{
+			Client:           c,
+			ClusterName:      cluster.Name,
+			APIVersion:       cluster.Spec.ControlPlaneRef.APIVersion,
+			ControlPlaneName: cluster.Spec.ControlPlaneRef.Name,
+			Namespace:        cluster.Spec.ControlPlaneRef.Namespace,
+		}
+	})
+}
+
+// getProvider returns the Provider for a given CAPI cluster. The registered
+// providers take precedence over the KubeconfigSourceMappings, which in turn
+// take precedence over the generic provider.
+func (c *ClusterProvider) getProvider(ctx context.Context, cluster *capiv1beta1.Cluster) (Provider, error) {
+	ref := cluster.Spec.ControlPlaneRef
+	if factory, ok := lookupFactory(ref.Kind, ref.APIVersion); ok {
+		return factory(c.Client, cluster), nil
+	}
+	p, err := c.getMappingProvider(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
 		return p, nil
-	default:
-		return nil, fmt.Errorf("controlPlaneRef kind %s unsupported", cluster.Spec.ControlPlaneRef.Kind)
 	}
+	if c.AllowGenericProvider {
+		var p Provider = genericControlPlane{
+			ClusterName: cluster.Name,
+			Namespace:   cluster.Namespace,
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("controlPlaneRef kind %s %w", ref.Kind, ErrUnsupported)
 }
 
 // IsCapiKubeconfig determines whether the secret provided is a CAPI kubeconfig
 // from a given control plane controller.
 func (c *ClusterProvider) IsCapiKubeconfig(ctx context.Context, secret *corev1.Secret, cluster *capiv1beta1.Cluster) (bool, error) {
-	p, err := c.getProvider(cluster)
+	p, err := c.getProvider(ctx, cluster)
 	if err != nil {
 		return false, err
 	}
@@ -79,10 +204,50 @@ func (c *ClusterProvider) IsCapiKubeconfig(ctx context.Context, secret *corev1.S
 
 // GetCapiKubeconfigNamespacedName retrieves the expected namespace and name
 // for a CAPI cluster's kubeconfig.
-func (c *ClusterProvider) GetCapiKubeconfigNamespacedName(cluster *capiv1beta1.Cluster) (types.NamespacedName, error) {
-	p, err := c.getProvider(cluster)
+func (c *ClusterProvider) GetCapiKubeconfigNamespacedName(ctx context.Context, cluster *capiv1beta1.Cluster) (types.NamespacedName, error) {
+	p, err := c.getProvider(ctx, cluster)
 	if err != nil {
 		return types.NamespacedName{}, err
 	}
 	return p.GetNamespacedName(), nil
 }
+
+// GetCapiKubeconfigKey retrieves the key under which the kubeconfig is
+// stored in a CAPI cluster's kubeconfig secret.
+func (c *ClusterProvider) GetCapiKubeconfigKey(ctx context.Context, cluster *capiv1beta1.Cluster) (string, error) {
+	p, err := c.getProvider(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
+	if kp, ok := p.(KubeconfigKeyProvider); ok {
+		return kp.GetKubeconfigKey(), nil
+	}
+	return defaultKubeconfigKey, nil
+}
+
+// GetCapiInClusterServer retrieves the address under which a CAPI cluster's
+// API server can be reached from within the management cluster. An empty
+// address is returned if the provider does not expose one.
+func (c *ClusterProvider) GetCapiInClusterServer(ctx context.Context, cluster *capiv1beta1.Cluster) (string, error) {
+	p, err := c.getProvider(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
+	if sp, ok := p.(InClusterServerProvider); ok {
+		return sp.GetInClusterServer(ctx)
+	}
+	return "", nil
+}
+
+// GetCapiAWSClusterName retrieves the name of the EKS cluster backing a CAPI
+// cluster. An empty name is returned if the provider is not backed by EKS.
+func (c *ClusterProvider) GetCapiAWSClusterName(ctx context.Context, cluster *capiv1beta1.Cluster) (string, error) {
+	p, err := c.getProvider(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
+	if ap, ok := p.(AWSClusterNameProvider); ok {
+		return ap.GetAWSClusterName(ctx)
+	}
+	return "", nil
+}
@@ -85,7 +85,7 @@ var _ = Describe("Provider functions", func() {
 				Client: k8sClient,
 			}
 			By("providing a namespaced cluster object")
-			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(&cluster)
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
 
 			By("asserting that the name is correct")
 			Expect(err).NotTo(HaveOccurred())
@@ -140,7 +140,7 @@ var _ = Describe("Provider functions", func() {
 			}
 
 			By("providing a namespaced cluster object")
-			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(&cluster)
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
 
 			By("asserting that the name is correct")
 			Expect(err).NotTo(HaveOccurred())
@@ -159,6 +159,36 @@ var _ = Describe("Provider functions", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(validated).To(BeTrue())
 		})
+
+		It("should return the in-cluster server address", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			service := corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName,
+					Namespace: clusterNamespace,
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Name: "https",
+							Port: 443,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: clusterNamespace}})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &service)).To(Succeed())
+
+			By("providing a namespaced cluster object")
+			server, err := clusterProvider.GetCapiInClusterServer(ctx, &cluster)
+
+			By("asserting that the address points at the vcluster service")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server).To(Equal("https://vcluster-cluster.vcluster-cluster-namespace.svc:443"))
+			Expect(k8sClient.Delete(ctx, &service)).To(Succeed())
+		})
 	})
 
 	Context("When a cluster has an AWS managed controlPlaneRef", func() {
@@ -204,7 +234,71 @@ var _ = Describe("Provider functions", func() {
 			}
 
 			By("providing a namespaced cluster object")
-			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(&cluster)
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+
+			By("asserting that the name is correct")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespacedName).To(Equal(types.NamespacedName{Name: kubeconfig.Name, Namespace: kubeconfig.Namespace}))
+		})
+
+		It("should validate the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+
+			By("providing a kubeconfig secret object")
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, &cluster)
+
+			By("asserting that the secret is an AWS managed kubeconfig")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+		})
+	})
+
+	Context("When a cluster has a v1beta1 AWS managed controlPlaneRef", func() {
+		clusterName := "eks-v1beta1-cluster"
+		clusterNamespace := "eks-v1beta1-cluster-namespace"
+		cluster := capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName,
+				Namespace: clusterNamespace,
+				UID:       types.UID(uuid.New().String()),
+			},
+			Spec: capiv1beta1.ClusterSpec{
+				ControlPlaneRef: &corev1.ObjectReference{
+					APIVersion: "controlplane.cluster.x-k8s.io/v1beta1",
+					Kind:       "AWSManagedControlPlane",
+					Name:       clusterName,
+					Namespace:  clusterNamespace,
+				},
+			},
+		}
+
+		var kubeconfig = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-user-kubeconfig",
+				Namespace: clusterNamespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         "controlplane.cluster.x-k8s.io/v1beta1",
+						BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+						Controller:         func(v bool) *bool { return &v }(true),
+						Kind:               "AWSManagedControlPlane",
+						UID:                cluster.GetUID(),
+					},
+				},
+			},
+			Type: "cluster.x-k8s.io/secret",
+			Data: map[string][]byte{},
+		}
+
+		It("should return the proper name for the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+
+			By("providing a namespaced cluster object")
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
 
 			By("asserting that the name is correct")
 			Expect(err).NotTo(HaveOccurred())
@@ -224,4 +318,67 @@ var _ = Describe("Provider functions", func() {
 			Expect(validated).To(BeTrue())
 		})
 	})
+
+	Context("When a cluster has an unknown controlPlaneRef", func() {
+		clusterName := "generic-cluster"
+		clusterNamespace := "generic-cluster-namespace"
+		cluster := capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName,
+				Namespace: clusterNamespace,
+				UID:       types.UID(uuid.New().String()),
+			},
+			Spec: capiv1beta1.ClusterSpec{
+				ControlPlaneRef: &corev1.ObjectReference{
+					APIVersion: "controlplane.cluster.x-k8s.io/v1alpha1",
+					Kind:       "UnknownControlPlane",
+					Name:       clusterName + "-control-plane",
+					Namespace:  clusterNamespace,
+				},
+			},
+		}
+
+		var kubeconfig = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-kubeconfig",
+				Namespace: clusterNamespace,
+				Labels: map[string]string{
+					capiv1beta1.ClusterNameLabel: clusterName,
+				},
+			},
+			Type: capiv1beta1.ClusterSecretType,
+			Data: map[string][]byte{},
+		}
+
+		It("should reject the cluster without the generic provider", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+
+			By("providing a namespaced cluster object")
+			_, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+
+			By("asserting that the control plane is unsupported")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should validate the kubeconfig with the generic provider", func() {
+			clusterProvider := ClusterProvider{
+				Client:               k8sClient,
+				AllowGenericProvider: true,
+			}
+
+			By("providing a namespaced cluster object")
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespacedName).To(Equal(types.NamespacedName{Name: kubeconfig.Name, Namespace: kubeconfig.Namespace}))
+
+			By("providing a kubeconfig secret object")
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, &cluster)
+
+			By("asserting that the secret is a Cluster API kubeconfig")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+		})
+	})
 })
@@ -0,0 +1,84 @@
+package providers
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Provider locates and validates the kubeconfig secret of clusters with a
+// given kind of control plane.
+type Provider interface {
+	// GetNamespacedName returns the namespace and name of the kubeconfig
+	// secret.
+	GetNamespacedName() types.NamespacedName
+
+	// IsKubeconfig determines whether the secret provided is the kubeconfig
+	// of the cluster.
+	IsKubeconfig(context.Context, *corev1.Secret) bool
+}
+
+// KubeconfigKeyProvider is implemented by providers that store the
+// kubeconfig under a key other than "value" in their secret.
+type KubeconfigKeyProvider interface {
+	GetKubeconfigKey() string
+}
+
+// InClusterServerProvider is implemented by providers whose API server is
+// reachable through a Service in the management cluster.
+type InClusterServerProvider interface {
+	GetInClusterServer(context.Context) (string, error)
+}
+
+// AWSClusterNameProvider is implemented by providers backed by an EKS
+// cluster, whose name may differ from the one of the CAPI cluster.
+type AWSClusterNameProvider interface {
+	GetAWSClusterName(context.Context) (string, error)
+}
+
+// Factory builds the Provider of a cluster. The client is the one of the
+// ClusterProvider, and can be used to read the control plane object.
+type Factory func(c client.Client, cluster *capiv1beta1.Cluster) Provider
+
+// registryKey identifies a control plane kind, optionally restricted to one
+// API version.
+type registryKey struct {
+	kind       string
+	apiVersion string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[registryKey]Factory{}
+)
+
+// Register makes a provider available for clusters whose controlPlaneRef has
+// the given kind and API version. An empty API version matches every version
+// of the kind, while factories registered for a specific version take
+// precedence. Registering the same kind and API version twice replaces the
+// previous factory, which allows the built-in providers to be overridden.
+//
+// Register is meant to be called from init functions or before the
+// controller is started.
+func Register(kind, apiVersion string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[registryKey{kind: kind, apiVersion: apiVersion}] = factory
+}
+
+// lookupFactory returns the factory registered for the kind and API version,
+// falling back to the one registered for every version of the kind.
+func lookupFactory(kind, apiVersion string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if f, ok := registry[registryKey{kind: kind, apiVersion: apiVersion}]; ok {
+		return f, true
+	}
+	f, ok := registry[registryKey{kind: kind}]
+	return f, ok
+}
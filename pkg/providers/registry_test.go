@@ -0,0 +1,89 @@
+package providers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// testControlPlane is a provider registered by the tests, storing its
+// kubeconfig under a fixed name.
+type testControlPlane struct {
+	Name      string
+	Namespace string
+	Key       string
+}
+
+func (t testControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: t.Name, Namespace: t.Namespace}
+}
+
+func (t testControlPlane) IsKubeconfig(_ context.Context, secret *corev1.Secret) bool {
+	return secret.Name == t.Name && secret.Namespace == t.Namespace
+}
+
+func (t testControlPlane) GetKubeconfigKey() string {
+	return t.Key
+}
+
+var _ = Describe("Provider registry tests", func() {
+	When("a provider is registered for a control plane kind", func() {
+		var cluster = capiv1beta1.Cluster{}
+
+		BeforeEach(func() {
+			Register("TestControlPlane", "", func(_ client.Client, cluster *capiv1beta1.Cluster) Provider {
+				return testControlPlane{Name: cluster.Name + "-any", Namespace: cluster.Namespace, Key: "any"}
+			})
+			Register("TestControlPlane", "controlplane.example.com/v1beta1", func(_ client.Client, cluster *capiv1beta1.Cluster) Provider {
+				return testControlPlane{Name: cluster.Name + "-v1beta1", Namespace: cluster.Namespace, Key: "v1beta1"}
+			})
+			cluster = capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster",
+					Namespace: "test-cluster-namespace",
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						APIVersion: "controlplane.example.com/v1alpha1",
+						Kind:       "TestControlPlane",
+						Name:       "test-cluster-control-plane",
+						Namespace:  "test-cluster-namespace",
+					},
+				},
+			}
+		})
+
+		It("should use the provider registered for every version", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespacedName.Name).To(Equal("test-cluster-any"))
+			key, err := clusterProvider.GetCapiKubeconfigKey(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key).To(Equal("any"))
+		})
+
+		It("should prefer the provider registered for the exact version", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			cluster.Spec.ControlPlaneRef.APIVersion = "controlplane.example.com/v1beta1"
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespacedName.Name).To(Equal("test-cluster-v1beta1"))
+			key, err := clusterProvider.GetCapiKubeconfigKey(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key).To(Equal("v1beta1"))
+		})
+	})
+})
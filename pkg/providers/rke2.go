@@ -0,0 +1,52 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const v1beta1RKE2ControlPlane = "controlplane.cluster.x-k8s.io/v1beta1"
+
+type rke2ControlPlane struct {
+	client.Client
+	ControlPlaneName string
+	ClusterName      string
+	Namespace        string
+	APIVersion       string
+}
+
+// GetNamespacedName returns the namespace and name of a cluster
+// with an RKE2-bootstrapped control plane.
+func (r rke2ControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-kubeconfig", r.ClusterName),
+		Namespace: r.Namespace,
+	}
+}
+
+// IsKubeconfig determines whether the secret provided is an
+// RKE2ControlPlane kubeconfig or not.
+func (r rke2ControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	switch r.APIVersion {
+	case v1beta1RKE2ControlPlane:
+		if !isClusterKubeconfig(ctx, secret, r.ClusterName) {
+			return false
+		}
+		return isOwnedByControlPlane(ctx, r.Client, secret,
+			r.APIVersion, string(rke2Kind), r.Namespace, r.ControlPlaneName,
+			controllerOwnership,
+		)
+	default:
+		logger.V(2).Info("APIVersion unsupported for RKE2ControlPlane",
+			"APIVersion", r.APIVersion,
+		)
+		return false
+	}
+}
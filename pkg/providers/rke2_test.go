@@ -0,0 +1,289 @@
+package providers
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("RKE2 provider tests", func() {
+	When("handling an RKE2 cluster with its control plane", func() {
+		var clusterName = "rke2-cluster"
+		var clusterNamespace = "rke2-cluster-namespace"
+		var controlPlane = unstructured.Unstructured{}
+		var cluster = capiv1beta1.Cluster{}
+		var kubeconfig = corev1.Secret{}
+
+		BeforeEach(func() {
+			clusterNamespace = fmt.Sprintf("%s-%d", clusterNamespace, time.Now().UnixMilli())
+			controlPlane = unstructured.Unstructured{}
+			controlPlane.SetAPIVersion(v1beta1RKE2ControlPlane)
+			controlPlane.SetKind("RKE2ControlPlane")
+			controlPlane.SetName(clusterName + "-control-plane")
+			controlPlane.SetNamespace(clusterNamespace)
+			cluster = capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName,
+					Namespace: clusterNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Name:       controlPlane.GetName(),
+						Namespace:  controlPlane.GetNamespace(),
+						APIVersion: v1beta1RKE2ControlPlane,
+						Kind:       "RKE2ControlPlane",
+					},
+				},
+			}
+			kubeconfig = corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName + "-kubeconfig",
+					Namespace: clusterNamespace,
+					Labels: map[string]string{
+						capiv1beta1.ClusterNameLabel: clusterName,
+					},
+				},
+				Type: capiv1beta1.ClusterSecretType,
+				Data: map[string][]byte{},
+			}
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: clusterNamespace}})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &controlPlane)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &cluster)).To(Succeed())
+			kubeconfig.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion:         controlPlane.GetAPIVersion(),
+					BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+					Controller:         func(v bool) *bool { return &v }(true),
+					Kind:               controlPlane.GetKind(),
+					Name:               controlPlane.GetName(),
+					UID:                controlPlane.GetUID(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &controlPlane)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &cluster)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		It("should return the proper name for the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+			By("providing a namespaced cluster object")
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+
+			By("asserting that the name is correct")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespacedName).To(Equal(types.NamespacedName{Name: kubeconfig.Name, Namespace: kubeconfig.Namespace}))
+		})
+
+		It("should validate the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+
+			By("providing a kubeconfig secret object")
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, &cluster)
+
+			By("asserting that the secret is an RKE2 kubeconfig")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+		})
+	})
+
+	When("handling a supported RKE2 cluster", func() {
+		var clusterName = "rke2-cluster"
+		var clusterNamespace = "rke2-cluster-namespace"
+		cluster := capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName,
+				Namespace: clusterNamespace,
+				UID:       types.UID(uuid.New().String()),
+			},
+			Spec: capiv1beta1.ClusterSpec{
+				ControlPlaneRef: &corev1.ObjectReference{
+					APIVersion: v1beta1RKE2ControlPlane,
+					Kind:       "RKE2ControlPlane",
+					Name:       clusterName + "-control-plane",
+					Namespace:  clusterNamespace,
+				},
+			},
+		}
+
+		// Missing secret type
+		var badConfig1 = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-kubeconfig",
+				Namespace: clusterNamespace,
+				Labels: map[string]string{
+					capiv1beta1.ClusterNameLabel: clusterName,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         cluster.Spec.ControlPlaneRef.APIVersion,
+						BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+						Controller:         func(v bool) *bool { return &v }(true),
+						Kind:               cluster.Spec.ControlPlaneRef.Kind,
+						Name:               cluster.Spec.ControlPlaneRef.Name,
+						UID:                cluster.GetUID(),
+					},
+				},
+			},
+			Data: map[string][]byte{},
+		}
+
+		// Missing label
+		var badConfig2 = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-kubeconfig",
+				Namespace: clusterNamespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         cluster.Spec.ControlPlaneRef.APIVersion,
+						BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+						Controller:         func(v bool) *bool { return &v }(true),
+						Kind:               cluster.Spec.ControlPlaneRef.Kind,
+						Name:               cluster.Spec.ControlPlaneRef.Name,
+						UID:                cluster.GetUID(),
+					},
+				},
+			},
+			Type: "cluster.x-k8s.io/secret",
+			Data: map[string][]byte{},
+		}
+
+		// Multiple owner references
+		var badConfig3 = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-kubeconfig",
+				Namespace: clusterNamespace,
+				Labels: map[string]string{
+					capiv1beta1.ClusterNameLabel: clusterName,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         cluster.Spec.ControlPlaneRef.APIVersion,
+						BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+						Controller:         func(v bool) *bool { return &v }(true),
+						Kind:               cluster.Spec.ControlPlaneRef.Kind,
+						Name:               cluster.Spec.ControlPlaneRef.Name,
+						UID:                cluster.GetUID(),
+					},
+					{
+						APIVersion: "fake.io/v1alpha1",
+						Kind:       "Fake",
+						Name:       "FakeObject",
+					},
+				},
+			},
+			Type: "cluster.x-k8s.io/secret",
+			Data: map[string][]byte{},
+		}
+
+		// Invalid owner reference
+		var badConfig4 = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-kubeconfig",
+				Namespace: clusterNamespace,
+				Labels: map[string]string{
+					capiv1beta1.ClusterNameLabel: clusterName,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: "fake.io/v1alpha1",
+						Kind:       "Fake",
+						Name:       "FakeObject",
+					},
+				},
+			},
+			Type: "cluster.x-k8s.io/secret",
+			Data: map[string][]byte{},
+		}
+
+		It("should reject all invalid kubeconfigs", func() {
+			var p = rke2ControlPlane{
+				Client:           k8sClient,
+				APIVersion:       cluster.Spec.ControlPlaneRef.APIVersion,
+				ControlPlaneName: cluster.Spec.ControlPlaneRef.Name,
+				Namespace:        cluster.Spec.ControlPlaneRef.Namespace,
+				ClusterName:      cluster.Name,
+			}
+			var validated bool
+			By("providing kubeconfig secrets with bad configs")
+			validated = p.IsKubeconfig(ctx, &badConfig1)
+			Expect(validated).To(BeFalse())
+			validated = p.IsKubeconfig(ctx, &badConfig2)
+			Expect(validated).To(BeFalse())
+			validated = p.IsKubeconfig(ctx, &badConfig3)
+			Expect(validated).To(BeFalse())
+			validated = p.IsKubeconfig(ctx, &badConfig4)
+			Expect(validated).To(BeFalse())
+		})
+	})
+
+	When("handling an unsupported RKE2 cluster", func() {
+		var clusterName = "rke2-cluster"
+		var clusterNamespace = "rke2-cluster-namespace"
+		// Unsupported API version
+		cluster := capiv1beta1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName,
+				Namespace: clusterNamespace,
+				UID:       types.UID(uuid.New().String()),
+			},
+			Spec: capiv1beta1.ClusterSpec{
+				ControlPlaneRef: &corev1.ObjectReference{
+					APIVersion: "controlplane.cluster.x-k8s.io/v1alpha1",
+					Kind:       "RKE2ControlPlane",
+					Name:       clusterName,
+					Namespace:  clusterNamespace,
+				},
+			},
+		}
+
+		var kubeconfig = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-kubeconfig",
+				Namespace: clusterNamespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         cluster.Spec.ControlPlaneRef.APIVersion,
+						BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+						Controller:         func(v bool) *bool { return &v }(true),
+						Kind:               "RKE2ControlPlane",
+						UID:                cluster.GetUID(),
+					},
+				},
+			},
+			Type: "cluster.x-k8s.io/secret",
+			Data: map[string][]byte{},
+		}
+
+		It("should reject the unsupported cluster", func() {
+			var p = rke2ControlPlane{
+				Client:           k8sClient,
+				APIVersion:       cluster.Spec.ControlPlaneRef.APIVersion,
+				ControlPlaneName: cluster.Spec.ControlPlaneRef.Name,
+				Namespace:        cluster.Spec.ControlPlaneRef.Namespace,
+				ClusterName:      cluster.Name,
+			}
+			var validated bool
+			By("trying to validate the kubeconfig")
+			validated = p.IsKubeconfig(ctx, &kubeconfig)
+			Expect(validated).To(BeFalse())
+		})
+	})
+})
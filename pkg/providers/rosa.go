@@ -0,0 +1,54 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const v1beta2ROSAControlPlane = "controlplane.cluster.x-k8s.io/v1beta2"
+
+// rosaControlPlane handles Red Hat OpenShift Service on AWS (ROSA) clusters
+// with hosted control planes managed by CAPA.
+type rosaControlPlane struct {
+	client.Client
+	ControlPlaneName string
+	ClusterName      string
+	Namespace        string
+	APIVersion       string
+}
+
+// GetNamespacedName returns the namespace and name of a cluster
+// with a ROSA control plane.
+func (r rosaControlPlane) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-kubeconfig", r.ClusterName),
+		Namespace: r.Namespace,
+	}
+}
+
+// IsKubeconfig determines whether the secret provided is a
+// ROSAControlPlane kubeconfig or not.
+func (r rosaControlPlane) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool {
+	logger := logf.FromContext(ctx).WithName(loggerName)
+	switch r.APIVersion {
+	case v1beta2ROSAControlPlane:
+		if !isClusterKubeconfig(ctx, secret, r.ClusterName) {
+			return false
+		}
+		return isOwnedByControlPlane(ctx, r.Client, secret,
+			r.APIVersion, string(rosaKind), r.Namespace, r.ControlPlaneName,
+			controllerOwnership,
+		)
+	default:
+		logger.V(2).Info("APIVersion unsupported for ROSAControlPlane",
+			"APIVersion", r.APIVersion,
+		)
+		return false
+	}
+}
@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("ROSA provider tests", func() {
+	When("handling a ROSA cluster with its control plane", func() {
+		var clusterName = "rosa-cluster"
+		var clusterNamespace = "rosa-cluster-namespace"
+		var controlPlane = unstructured.Unstructured{}
+		var cluster = capiv1beta1.Cluster{}
+		var kubeconfig = corev1.Secret{}
+
+		BeforeEach(func() {
+			clusterNamespace = fmt.Sprintf("%s-%d", clusterNamespace, time.Now().UnixMilli())
+			controlPlane = unstructured.Unstructured{}
+			controlPlane.SetAPIVersion(v1beta2ROSAControlPlane)
+			controlPlane.SetKind("ROSAControlPlane")
+			controlPlane.SetName(clusterName + "-control-plane")
+			controlPlane.SetNamespace(clusterNamespace)
+			cluster = capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName,
+					Namespace: clusterNamespace,
+				},
+				Spec: capiv1beta1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						Name:       controlPlane.GetName(),
+						Namespace:  controlPlane.GetNamespace(),
+						APIVersion: v1beta2ROSAControlPlane,
+						Kind:       "ROSAControlPlane",
+					},
+				},
+			}
+			kubeconfig = corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName + "-kubeconfig",
+					Namespace: clusterNamespace,
+					Labels: map[string]string{
+						capiv1beta1.ClusterNameLabel: clusterName,
+					},
+				},
+				Type: capiv1beta1.ClusterSecretType,
+				Data: map[string][]byte{},
+			}
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: clusterNamespace}})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &controlPlane)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &cluster)).To(Succeed())
+			kubeconfig.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion:         controlPlane.GetAPIVersion(),
+					BlockOwnerDeletion: func(v bool) *bool { return &v }(true),
+					Controller:         func(v bool) *bool { return &v }(true),
+					Kind:               controlPlane.GetKind(),
+					Name:               controlPlane.GetName(),
+					UID:                controlPlane.GetUID(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &controlPlane)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &cluster)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &kubeconfig)).To(Succeed())
+		})
+
+		It("should validate the kubeconfig", func() {
+			clusterProvider := ClusterProvider{
+				Client: k8sClient,
+			}
+
+			By("providing a namespaced cluster object")
+			namespacedName, err := clusterProvider.GetCapiKubeconfigNamespacedName(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespacedName).To(Equal(types.NamespacedName{Name: kubeconfig.Name, Namespace: kubeconfig.Namespace}))
+
+			By("providing a kubeconfig secret object")
+			validated, err := clusterProvider.IsCapiKubeconfig(ctx, &kubeconfig, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validated).To(BeTrue())
+		})
+
+		It("should reject v1beta1, which ROSAControlPlane was never served as", func() {
+			var p = rosaControlPlane{
+				Client:           k8sClient,
+				APIVersion:       v1beta1AWSManagedControlPlane,
+				ControlPlaneName: controlPlane.GetName(),
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			By("trying to validate the kubeconfig")
+			Expect(p.IsKubeconfig(ctx, &kubeconfig)).To(BeFalse())
+		})
+
+		It("should reject a kubeconfig owned by an AWSManagedControlPlane", func() {
+			var p = rosaControlPlane{
+				Client:           k8sClient,
+				APIVersion:       v1beta2ROSAControlPlane,
+				ControlPlaneName: controlPlane.GetName(),
+				Namespace:        clusterNamespace,
+				ClusterName:      clusterName,
+			}
+			secret := kubeconfig.DeepCopy()
+			secret.OwnerReferences[0].Kind = string(awsManagedKind)
+			secret.OwnerReferences[0].UID = types.UID("aws-managed-control-plane")
+
+			By("providing a kubeconfig secret controlled by another kind")
+			Expect(p.IsKubeconfig(ctx, secret)).To(BeFalse())
+		})
+	})
+})
@@ -17,6 +17,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
+	capargov1alpha1 "github.com/superorbital/capargo/api/v1alpha1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -43,11 +44,32 @@ type crdInfo struct {
 	Names        apiextensionsv1.CustomResourceDefinitionNames
 	Scope        apiextensionsv1.ResourceScope
 	GroupVersion schema.GroupVersion
+	// ServedVersions are the versions served besides the storage version
+	// of GroupVersion.
+	ServedVersions []string
 }
 
 func createCRDs(info []crdInfo) []*apiextensionsv1.CustomResourceDefinition {
 	crds := []*apiextensionsv1.CustomResourceDefinition{}
 	for i := range info {
+		versions := []apiextensionsv1.CustomResourceDefinitionVersion{}
+		for _, version := range append([]string{info[i].GroupVersion.Version}, info[i].ServedVersions...) {
+			versions = append(versions, apiextensionsv1.CustomResourceDefinitionVersion{
+				Name:    version,
+				Served:  true,
+				Storage: version == info[i].GroupVersion.Version,
+				Schema: &apiextensionsv1.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+						Type:                   "object",
+						XPreserveUnknownFields: func(v bool) *bool { return &v }(true),
+					},
+				},
+				AdditionalPrinterColumns: []apiextensionsv1.CustomResourceColumnDefinition{},
+				Subresources: &apiextensionsv1.CustomResourceSubresources{
+					Status: &apiextensionsv1.CustomResourceSubresourceStatus{},
+				},
+			})
+		}
 		crd := apiextensionsv1.CustomResourceDefinition{
 			TypeMeta: metav1.TypeMeta{
 				Kind:       info[i].Names.Kind,
@@ -57,26 +79,10 @@ func createCRDs(info []crdInfo) []*apiextensionsv1.CustomResourceDefinition {
 				Name: fmt.Sprintf("%s.%s", info[i].Names.Plural, info[i].GroupVersion.Group),
 			},
 			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
-				Group: info[i].GroupVersion.Group,
-				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
-					{
-						Name:    info[i].GroupVersion.Version,
-						Served:  true,
-						Storage: true,
-						Schema: &apiextensionsv1.CustomResourceValidation{
-							OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
-								Type:                   "object",
-								XPreserveUnknownFields: func(v bool) *bool { return &v }(true),
-							},
-						},
-						AdditionalPrinterColumns: []apiextensionsv1.CustomResourceColumnDefinition{},
-						Subresources: &apiextensionsv1.CustomResourceSubresources{
-							Status: &apiextensionsv1.CustomResourceSubresourceStatus{},
-						},
-					},
-				},
-				Scope: info[i].Scope,
-				Names: info[i].Names,
+				Group:    info[i].GroupVersion.Group,
+				Versions: versions,
+				Scope:    info[i].Scope,
+				Names:    info[i].Names,
 			},
 		}
 		crds = append(crds, &crd)
@@ -105,6 +111,124 @@ var _ = BeforeSuite(func() {
 			Scope:        apiextensionsv1.NamespaceScoped,
 			GroupVersion: kubeadmv1beta1.GroupVersion,
 		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "rke2controlplane",
+				Plural:   "rke2controlplanes",
+				Kind:     "RKE2ControlPlane",
+			},
+			Scope: apiextensionsv1.NamespaceScoped,
+			GroupVersion: schema.GroupVersion{
+				Group:   "controlplane.cluster.x-k8s.io",
+				Version: "v1beta1",
+			},
+		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "kthreescontrolplane",
+				Plural:   "kthreescontrolplanes",
+				Kind:     "KThreesControlPlane",
+			},
+			Scope: apiextensionsv1.NamespaceScoped,
+			GroupVersion: schema.GroupVersion{
+				Group:   "controlplane.cluster.x-k8s.io",
+				Version: "v1beta2",
+			},
+			ServedVersions: []string{"v1beta1"},
+		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "k0smotroncontrolplane",
+				Plural:   "k0smotroncontrolplanes",
+				Kind:     "K0smotronControlPlane",
+			},
+			Scope: apiextensionsv1.NamespaceScoped,
+			GroupVersion: schema.GroupVersion{
+				Group:   "controlplane.cluster.x-k8s.io",
+				Version: "v1beta1",
+			},
+		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "k0scontrolplane",
+				Plural:   "k0scontrolplanes",
+				Kind:     "K0sControlPlane",
+			},
+			Scope: apiextensionsv1.NamespaceScoped,
+			GroupVersion: schema.GroupVersion{
+				Group:   "controlplane.cluster.x-k8s.io",
+				Version: "v1beta1",
+			},
+		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "rosacontrolplane",
+				Plural:   "rosacontrolplanes",
+				Kind:     "ROSAControlPlane",
+			},
+			Scope: apiextensionsv1.NamespaceScoped,
+			GroupVersion: schema.GroupVersion{
+				Group:   "controlplane.cluster.x-k8s.io",
+				Version: "v1beta2",
+			},
+		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "tenantcontrolplane",
+				Plural:   "tenantcontrolplanes",
+				Kind:     "TenantControlPlane",
+			},
+			Scope: apiextensionsv1.NamespaceScoped,
+			GroupVersion: schema.GroupVersion{
+				Group:   "kamaji.clastix.io",
+				Version: "v1alpha1",
+			},
+		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "azuremanagedcontrolplane",
+				Plural:   "azuremanagedcontrolplanes",
+				Kind:     "AzureManagedControlPlane",
+			},
+			Scope: apiextensionsv1.NamespaceScoped,
+			GroupVersion: schema.GroupVersion{
+				Group:   "infrastructure.cluster.x-k8s.io",
+				Version: "v1beta1",
+			},
+		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "gcpmanagedcontrolplane",
+				Plural:   "gcpmanagedcontrolplanes",
+				Kind:     "GCPManagedControlPlane",
+			},
+			Scope: apiextensionsv1.NamespaceScoped,
+			GroupVersion: schema.GroupVersion{
+				Group:   "infrastructure.cluster.x-k8s.io",
+				Version: "v1beta1",
+			},
+		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "customcontrolplane",
+				Plural:   "customcontrolplanes",
+				Kind:     "CustomControlPlane",
+			},
+			Scope: apiextensionsv1.NamespaceScoped,
+			GroupVersion: schema.GroupVersion{
+				Group:   "controlplane.example.com",
+				Version: "v1alpha1",
+			},
+		},
+		{
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Singular: "kubeconfigsourcemapping",
+				Plural:   "kubeconfigsourcemappings",
+				Kind:     "KubeconfigSourceMapping",
+			},
+			Scope:        apiextensionsv1.ClusterScoped,
+			GroupVersion: capargov1alpha1.GroupVersion,
+		},
 	}
 	testCRDs := createCRDs(crds)
 	By("bootstrapping the envtest test environment")
@@ -125,6 +249,9 @@ var _ = BeforeSuite(func() {
 	err = kubeadmv1beta1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = capargov1alpha1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
 	// Create client for envTest
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 	Expect(err).NotTo(HaveOccurred())
@@ -8,9 +8,11 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type vCluster struct {
+	client.Client
 	Name       string
 	Namespace  string
 	APIVersion string
@@ -53,3 +55,9 @@ func (v vCluster) IsKubeconfig(ctx context.Context, secret *corev1.Secret) bool
 		return false
 	}
 }
+
+// GetInClusterServer returns the address of the Service exposing the
+// vCluster API server within the management cluster.
+func (v vCluster) GetInClusterServer(ctx context.Context) (string, error) {
+	return getServiceServer(ctx, v.Client, v.Name, v.Namespace, "https")
+}
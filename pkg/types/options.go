@@ -1,10 +1,76 @@
 package types
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// AdoptionPolicy defines how ArgoCD cluster secrets that were not created by
+// capargo are handled when they register the same cluster.
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyAdopt replaces them with the capargo secret.
+	AdoptionPolicyAdopt AdoptionPolicy = "adopt"
+
+	// AdoptionPolicySkip leaves them as is, and does not register the
+	// cluster.
+	AdoptionPolicySkip AdoptionPolicy = "skip"
+
+	// AdoptionPolicyFail leaves them as is, and fails the registration.
+	AdoptionPolicyFail AdoptionPolicy = "fail"
+)
 
 type Options struct {
 	ClusterID        string
 	ClusterNamespace string
 	ArgoNamespace    string
 	Timeout          time.Duration
+	InClusterServer  bool
+
+	// AdoptionPolicy defines how ArgoCD cluster secrets that were not
+	// created by capargo are handled. Secrets are adopted by default.
+	AdoptionPolicy AdoptionPolicy
+
+	// WaitForApplications delays the removal of the ArgoCD cluster secret of
+	// a deleted cluster until no ArgoCD Application targets it anymore.
+	WaitForApplications bool
+
+	// ClusterSelector selects the clusters to register. A nil selector
+	// selects every cluster.
+	ClusterSelector labels.Selector
+
+	// ArgoClusterNameTemplate and SecretNameTemplate are the Go templates
+	// of the ArgoCD cluster name and of the ArgoCD cluster secret name.
+	ArgoClusterNameTemplate string
+	SecretNameTemplate      string
+
+	// NamespaceProjects maps Cluster namespaces onto the ArgoCD project of
+	// their clusters.
+	NamespaceProjects map[string]string
+
+	// PropagateLabels and PropagateLabelPrefixes select the Cluster labels
+	// copied onto the ArgoCD cluster secret, by exact key or by prefix.
+	PropagateLabels        []string
+	PropagateLabelPrefixes []string
+
+	// PropagateAnnotations and PropagateAnnotationPrefixes select the
+	// Cluster annotations copied onto the ArgoCD cluster secret, by exact
+	// key or by prefix.
+	PropagateAnnotations        []string
+	PropagateAnnotationPrefixes []string
+}
+
+// WatchNamespaces returns the namespaces listed in ClusterNamespace, which
+// holds a comma-separated list. An empty list means all namespaces.
+func (o Options) WatchNamespaces() []string {
+	namespaces := []string{}
+	for _, ns := range strings.Split(o.ClusterNamespace, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
 }